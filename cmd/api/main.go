@@ -6,16 +6,22 @@ import (
 	"log"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"expenseapi/internal/auth"
 	"expenseapi/internal/config"
+	"expenseapi/internal/currency"
 	"expenseapi/internal/handler"
+	"expenseapi/internal/mailer"
 	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/oidc"
+	"expenseapi/internal/password"
 	"expenseapi/internal/repository"
 	"expenseapi/internal/service"
 
 	scalargo "github.com/bdpiprava/scalar-go"
-	"github.com/bdpiprava/scalar-go/model"
+	scalarmodel "github.com/bdpiprava/scalar-go/model"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -30,16 +36,137 @@ func main() {
 	}
 	defer dbpool.Close()
 
+	// Novas senhas usam Argon2id com os parâmetros configurados; hashes bcrypt
+	// continuam aceitos e são atualizados no próximo login
+	if err := cfg.Auth.PasswordHash.Validate(); err != nil {
+		log.Fatalf("Erro na configuração do hash de senhas: %v", err)
+	}
+	password.SetDefault(password.NewManager(
+		password.NewArgon2idHasher(password.Argon2idParams{
+			Memory:      uint32(cfg.Auth.PasswordHash.Memory),
+			Iterations:  uint32(cfg.Auth.PasswordHash.Iterations),
+			Parallelism: uint8(cfg.Auth.PasswordHash.Parallelism),
+			SaltLength:  password.DefaultArgon2idParams.SaltLength,
+			KeyLength:   password.DefaultArgon2idParams.KeyLength,
+		}),
+		password.NewBcryptHasher(0),
+	))
+
 	// Inicializa os serviços
-	jwtService := auth.NewJWTService(cfg.JWT.Secret, cfg.JWT.ExpiresIn, cfg.JWT.RefreshToken)
+	jwtService, err := auth.NewJWTServiceFromConfig(cfg.JWT)
+	if err != nil {
+		log.Fatalf("Erro ao carregar as chaves de assinatura JWT: %v", err)
+	}
+	if len(cfg.JWT.SigningKeys) == 0 && cfg.JWT.Secret == "seu_segredo_jwt" {
+		log.Printf("Aviso: JWT_SECRET usa o valor padrão; defina um segredo ou configure JWT_SIGNING_KEYS")
+	}
 	userRepo := repository.NewUserRepository(dbpool)
-	authService := service.NewAuthService(userRepo, jwtService)
+	refreshRepo := repository.NewRefreshTokenRepository(dbpool)
+
+	// Carrega a lista de tokens revogados e a mantém sincronizada com o banco
+	revocations := auth.NewRevocationList(repository.NewRevocationRepository(dbpool))
+	if err := revocations.Sync(context.Background()); err != nil {
+		log.Fatalf("Erro ao carregar tokens revogados: %v", err)
+	}
+	go revocations.Start(context.Background(), time.Minute)
+
+	// Inicializa a verificação de email
+	mail := mailer.New(cfg.Mail)
+	emailTokenRepo := repository.NewEmailTokenRepository(dbpool)
+	verificationService := service.NewVerificationService(userRepo, emailTokenRepo, mail, cfg.App.URL, cfg.Auth.EmailTokenTTL)
+	verificationHandler := handler.NewVerificationHandler(verificationService)
+
+	// Limita as tentativas de login por conta e por IP
+	var attemptStore auth.AttemptStore = repository.NewLoginAttemptRepository(dbpool)
+	if cfg.Auth.Login.Store == "memory" {
+		attemptStore = auth.NewMemoryAttemptStore()
+	}
+	loginLimiter := auth.NewLoginLimiter(attemptStore,
+		auth.LimitPolicy{Threshold: cfg.Auth.Login.MaxAttempts, BaseDelay: cfg.Auth.Login.LockoutBase, MaxDelay: cfg.Auth.Login.LockoutMax, Window: cfg.Auth.Login.Window},
+		auth.LimitPolicy{Threshold: cfg.Auth.Login.IPMaxAttempts, BaseDelay: cfg.Auth.Login.LockoutBase, MaxDelay: cfg.Auth.Login.LockoutMax, Window: cfg.Auth.Login.Window},
+	)
+	go loginLimiter.Start(context.Background(), 10*time.Minute)
+
+	// Tokens de acesso pessoal, aceitos pelo AuthMiddleware junto com os JWTs
+	personalTokenService := service.NewPersonalTokenService(repository.NewPersonalTokenRepository(dbpool))
+	personalTokenHandler := handler.NewPersonalTokenHandler(personalTokenService)
+
+	// Cada login registra uma sessão, que pode ser encerrada remotamente
+	sessionService := service.NewSessionService(repository.NewSessionRepository(dbpool), refreshRepo, revocations, jwtService.AccessExpiry())
+
+	// Política aplicada às novas senhas, opcionalmente com a lista de senhas vazadas
+	var breachedPasswords *password.BreachedList
+	if cfg.Auth.PasswordPolicy.BreachedList != "" {
+		breachedPasswords, err = password.LoadBreachedList(cfg.Auth.PasswordPolicy.BreachedList)
+		if err != nil {
+			log.Fatalf("Erro ao carregar a lista de senhas vazadas: %v", err)
+		}
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(cfg.Auth.PasswordPolicy.MinLength, cfg.Auth.PasswordPolicy.RequiredClasses, breachedPasswords)
+	if err != nil {
+		log.Fatalf("Erro na configuração da política de senhas: %v", err)
+	}
+
+	authService := service.NewAuthService(userRepo, refreshRepo, jwtService, revocations, verificationService, service.UnverifiedAccess(cfg.Auth.UnverifiedAccess), loginLimiter, personalTokenService, sessionService, passwordPolicy)
 	authHandler := handler.NewAuthHandler(authService)
+	sessionHandler := handler.NewSessionHandler(authService)
+
+	// Inicializa o fluxo de redefinição de senha
+	resetRepo := repository.NewPasswordResetRepository(dbpool)
+	passwordResetService := service.NewPasswordResetService(userRepo, resetRepo, authService, mail, cfg.App.URL, cfg.Auth.PasswordResetTTL)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+
+	// Inicializa os serviços da conta do usuário
+	accountService := service.NewAccountService(userRepo, emailTokenRepo, authService, mail, cfg.App.URL, cfg.Auth.EmailTokenTTL)
+	accountHandler := handler.NewAccountHandler(accountService)
+
+	// Inicializa a autenticação em dois fatores
+	mfaService := service.NewMFAService(userRepo, repository.NewMFARepository(dbpool), jwtService, authService, loginLimiter, cfg.App.Name)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+
+	// Inicializa o login único pelos provedores OIDC configurados
+	var oidcProviders []*oidc.Provider
+	for _, providerCfg := range cfg.OIDC {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(providerCfg, nil))
+	}
+	oidcService := service.NewOIDCService(oidcProviders, repository.NewOIDCRepository(dbpool), userRepo, authService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+
+	// Inicializa a API administrativa
+	adminHandler := handler.NewAdminHandler(service.NewAdminService(userRepo, authService))
+
+	// Inicializa as cotações de moedas: as gravadas no banco e as dos arquivos
+	// configurados, que são importados a cada inicialização. As cotações
+	// importadas pela API em outras instâncias chegam na recarga periódica
+	currencyService := service.NewCurrencyService(currency.NewTable(), repository.NewExchangeRateRepository(dbpool), userRepo)
+	if err := currencyService.Load(context.Background()); err != nil {
+		log.Fatalf("Erro ao carregar as cotações: %v", err)
+	}
+	for _, path := range cfg.Currency.RateFiles {
+		imported, err := currencyService.ImportFile(context.Background(), path)
+		if err != nil {
+			log.Fatalf("Erro ao importar as cotações: %v", err)
+		}
+		log.Printf("%d cotações importadas de %s", imported, path)
+	}
+	go currencyService.Start(context.Background(), 5*time.Minute)
+	currencyHandler := handler.NewCurrencyHandler(currencyService)
+
+	// Inicializa as categorias de despesas de cada usuário
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(dbpool))
+	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	// Inicializa os serviços de despesas
 	expenseRepo := repository.NewExpenseRepository(dbpool)
-	expenseService := service.NewExpenseService(expenseRepo)
+	expenseService := service.NewExpenseService(expenseRepo, categoryService, currencyService)
 	expenseHandler := handler.NewExpenseHandler(expenseService)
+	tagHandler := handler.NewTagHandler(service.NewTagService(repository.NewTagRepository(dbpool)))
+
+	// Exportação dos dados e exclusão da conta; as contas com exclusão vencida
+	// são removidas periodicamente
+	privacyService := service.NewPrivacyService(userRepo, expenseRepo, authService, cfg.Auth.DeletionGrace)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	go privacyService.Start(context.Background(), time.Hour)
 
 	// Configuração do router
 	mux := http.NewServeMux()
@@ -49,16 +176,70 @@ func main() {
 		fmt.Fprintf(w, "API de Controle de Despesas - v1.0.0")
 	})
 
+	// Chaves públicas para validação dos tokens por outros serviços
+	mux.HandleFunc("GET /.well-known/jwks.json", handler.NewJWKSHandler(jwtService).Keys)
+
 	// Rotas de autenticação
 	mux.HandleFunc("POST /api/v1/auth/register", authHandler.Register)
 	mux.HandleFunc("POST /api/v1/auth/login", authHandler.Login)
+	mux.HandleFunc("POST /api/v1/auth/refresh", authHandler.Refresh)
+	mux.HandleFunc("POST /api/v1/auth/logout", middleware.AuthMiddleware(authService, middleware.RequireSession(authHandler.Logout)))
+	mux.HandleFunc("POST /api/v1/auth/logout-all", middleware.AuthMiddleware(authService, middleware.RequireSession(authHandler.LogoutAll)))
+	mux.HandleFunc("POST /api/v1/auth/password/forgot", passwordResetHandler.Forgot)
+	mux.HandleFunc("POST /api/v1/auth/password/reset", passwordResetHandler.Reset)
+	mux.HandleFunc("POST /api/v1/auth/email/confirm", accountHandler.ConfirmEmail)
+	mux.HandleFunc("POST /api/v1/auth/verify-email", verificationHandler.Verify)
+	mux.HandleFunc("POST /api/v1/auth/resend-verification", verificationHandler.Resend)
+	mux.HandleFunc("POST /api/v1/auth/2fa/verify", mfaHandler.Verify)
+	mux.HandleFunc("GET /api/v1/auth/oidc/{provider}/start", oidcHandler.Start)
+	mux.HandleFunc("GET /api/v1/auth/oidc/{provider}/callback", oidcHandler.Callback)
+
+	// Rotas da conta do usuário autenticado (não aceitam tokens pessoais)
+	mux.HandleFunc("GET /api/v1/me", middleware.AuthMiddleware(authService, middleware.RequireSession(accountHandler.Profile)))
+	mux.HandleFunc("DELETE /api/v1/me", middleware.AuthMiddleware(authService, middleware.RequireSession(privacyHandler.Delete)))
+	mux.HandleFunc("GET /api/v1/me/export", middleware.AuthMiddleware(authService, middleware.RequireSession(privacyHandler.Export)))
+	mux.HandleFunc("PUT /api/v1/me/password", middleware.AuthMiddleware(authService, middleware.RequireSession(accountHandler.ChangePassword)))
+	mux.HandleFunc("PUT /api/v1/me/email", middleware.AuthMiddleware(authService, middleware.RequireSession(accountHandler.ChangeEmail)))
+	mux.HandleFunc("PUT /api/v1/me/currency", middleware.AuthMiddleware(authService, middleware.RequireSession(currencyHandler.SetBaseCurrency)))
+	mux.HandleFunc("POST /api/v1/me/2fa/setup", middleware.AuthMiddleware(authService, middleware.RequireSession(mfaHandler.Setup)))
+	mux.HandleFunc("POST /api/v1/me/2fa/confirm", middleware.AuthMiddleware(authService, middleware.RequireSession(mfaHandler.Confirm)))
+	mux.HandleFunc("POST /api/v1/me/tokens", middleware.AuthMiddleware(authService, middleware.RequireSession(personalTokenHandler.Create)))
+	mux.HandleFunc("GET /api/v1/me/tokens", middleware.AuthMiddleware(authService, middleware.RequireSession(personalTokenHandler.List)))
+	mux.HandleFunc("DELETE /api/v1/me/tokens/{id}", middleware.AuthMiddleware(authService, middleware.RequireSession(personalTokenHandler.Delete)))
+	mux.HandleFunc("GET /api/v1/me/sessions", middleware.AuthMiddleware(authService, middleware.RequireSession(sessionHandler.List)))
+	mux.HandleFunc("DELETE /api/v1/me/sessions/{id}", middleware.AuthMiddleware(authService, middleware.RequireSession(sessionHandler.Delete)))
 
-	// Rotas de despesas (protegidas por autenticação)
-	mux.HandleFunc("POST /api/v1/expenses", middleware.AuthMiddleware(jwtService, expenseHandler.Create))
-	mux.HandleFunc("GET /api/v1/expenses", middleware.AuthMiddleware(jwtService, expenseHandler.List))
-	mux.HandleFunc("GET /api/v1/expenses/{id}", middleware.AuthMiddleware(jwtService, expenseHandler.GetByID))
-	mux.HandleFunc("PUT /api/v1/expenses/{id}", middleware.AuthMiddleware(jwtService, expenseHandler.Update))
-	mux.HandleFunc("DELETE /api/v1/expenses/{id}", middleware.AuthMiddleware(jwtService, expenseHandler.Delete))
+	// Rotas administrativas (exigem uma sessão de administrador)
+	admin := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.AuthMiddleware(authService, middleware.RequireSession(middleware.RequireRole(model.RoleAdmin, next)))
+	}
+	mux.HandleFunc("GET /api/v1/admin/users", admin(adminHandler.ListUsers))
+	mux.HandleFunc("PUT /api/v1/admin/users/{id}/role", admin(adminHandler.SetRole))
+	mux.HandleFunc("POST /api/v1/admin/users/{id}/disable", admin(adminHandler.Disable))
+	mux.HandleFunc("POST /api/v1/admin/users/{id}/enable", admin(adminHandler.Enable))
+	mux.HandleFunc("POST /api/v1/admin/users/{id}/logout", admin(adminHandler.ForceLogout))
+	mux.HandleFunc("POST /api/v1/admin/exchange-rates", admin(currencyHandler.ImportRates))
+
+	// Rotas de despesas (protegidas por autenticação; escrita exige acesso completo
+	// e, para tokens pessoais, o escopo correspondente)
+	readExpenses := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, next))
+	}
+	writeExpenses := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(next)))
+	}
+	mux.HandleFunc("POST /api/v1/expenses", writeExpenses(expenseHandler.Create))
+	mux.HandleFunc("GET /api/v1/expenses", readExpenses(expenseHandler.List))
+	mux.HandleFunc("GET /api/v1/expenses/summary", readExpenses(expenseHandler.Summary))
+	mux.HandleFunc("GET /api/v1/expenses/search", readExpenses(expenseHandler.Search))
+	mux.HandleFunc("GET /api/v1/expenses/{id}", readExpenses(expenseHandler.GetByID))
+	mux.HandleFunc("PUT /api/v1/expenses/{id}", writeExpenses(expenseHandler.Update))
+	mux.HandleFunc("DELETE /api/v1/expenses/{id}", writeExpenses(expenseHandler.Delete))
+	mux.HandleFunc("GET /api/v1/categories", readExpenses(categoryHandler.List))
+	mux.HandleFunc("POST /api/v1/categories", writeExpenses(categoryHandler.Create))
+	mux.HandleFunc("PUT /api/v1/categories/{id}", writeExpenses(categoryHandler.Update))
+	mux.HandleFunc("DELETE /api/v1/categories/{id}", writeExpenses(categoryHandler.Delete))
+	mux.HandleFunc("GET /api/v1/tags", readExpenses(tagHandler.List))
 
 	// Rota para a documentação Scalar
 	mux.HandleFunc("GET /docs", func(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +247,7 @@ func main() {
 		content, err := scalargo.New(
 			docsDir,
 			scalargo.WithBaseFileName("api.yaml"),
-			scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			scalargo.WithSpecModifier(func(spec *scalarmodel.Spec) *scalarmodel.Spec {
 				spec.Info.Title = "API de Controle de Despesas"
 				return spec
 			}),
@@ -81,10 +262,20 @@ func main() {
 		w.Write([]byte(content))
 	})
 
+	// Atrás de um proxy confiável, o IP do cliente vem do X-Forwarded-For
+	var httpHandler http.Handler = mux
+	if cfg.Server.TrustProxy {
+		trustedProxies, err := middleware.ParseTrustedProxies(cfg.Server.TrustedProxies)
+		if err != nil {
+			log.Fatalf("Erro na configuração de proxies confiáveis: %v", err)
+		}
+		httpHandler = middleware.RealIP(trustedProxies, httpHandler)
+	}
+
 	// Configuração do servidor
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Server.Port),
-		Handler: middleware.CORS(mux),
+		Handler: middleware.CORS(httpHandler),
 	}
 
 	log.Printf("Iniciando servidor na porta %s", server.Addr)
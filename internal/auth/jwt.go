@@ -1,78 +1,256 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"time"
 
+	"expenseapi/internal/config"
+	"expenseapi/internal/model"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// TokenType identifica a finalidade de um token
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+	// TokenTypeMFA é emitido após a senha correta quando a conta exige o
+	// segundo fator; só serve para concluir o login
+	TokenTypeMFA TokenType = "mfa_pending"
+	// TokenTypePersonal identifica as claims de um token de acesso pessoal.
+	// Esses tokens são opacos e nunca são emitidos como JWT
+	TokenTypePersonal TokenType = "personal"
+)
+
+// mfaTokenExpiry é a validade do token intermediário do login com 2FA
+const mfaTokenExpiry = 5 * time.Minute
+
 type JWTService struct {
-	secretKey     string
+	// keys são as chaves HS256 derivadas do segredo; nil quando o serviço usa
+	// apenas chaves assimétricas
+	keys          map[TokenType][]byte
+	keySet        *KeySet
 	expiresIn     time.Duration
 	refreshExpiry time.Duration
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID   string     `json:"user_id"`
+	Type     TokenType  `json:"token_type"`
+	ReadOnly bool       `json:"read_only,omitempty"`
+	Role     model.Role `json:"role,omitempty"`
+	// Scopes limita as operações permitidas; vazio em tokens de sessão, que têm acesso completo
+	Scopes []string `json:"scopes,omitempty"`
+	// SessionID identifica a sessão de login que emitiu o token de acesso
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasScope indica se as claims permitem o escopo informado
+func (c *Claims) HasScope(scope string) bool {
+	if c.Type != TokenTypePersonal {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenOption customiza as claims dos tokens gerados
+type TokenOption func(*Claims)
+
+// WithRole inclui o papel do usuário no token
+func WithRole(role model.Role) TokenOption {
+	return func(c *Claims) {
+		c.Role = role
+	}
+}
+
+// WithReadOnly restringe o token a operações de leitura
+func WithReadOnly(readOnly bool) TokenOption {
+	return func(c *Claims) {
+		c.ReadOnly = readOnly
+	}
+}
+
+// WithSession vincula o token à sessão de login, permitindo revogá-lo com ela
+func WithSession(sessionID string) TokenOption {
+	return func(c *Claims) {
+		c.SessionID = sessionID
+	}
+}
+
 func NewJWTService(secretKey string, expiresIn, refreshExpiry time.Duration) *JWTService {
 	return &JWTService{
-		secretKey:     secretKey,
+		// Cada tipo de token é assinado com uma chave própria derivada do segredo,
+		// de modo que um refresh token nunca é uma assinatura válida de acesso
+		keys: map[TokenType][]byte{
+			TokenTypeAccess:  deriveKey(secretKey, TokenTypeAccess),
+			TokenTypeRefresh: deriveKey(secretKey, TokenTypeRefresh),
+			TokenTypeMFA:     deriveKey(secretKey, TokenTypeMFA),
+		},
 		expiresIn:     expiresIn,
 		refreshExpiry: refreshExpiry,
 	}
 }
 
-func (s *JWTService) GenerateToken(userID string) (string, string, error) {
-	// Token de acesso
-	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.expiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// NewJWTServiceWithKeys cria um serviço que assina com a chave ativa do conjunto
+// (RS256 ou EdDSA). Se legacySecret for informado, tokens HS256 emitidos antes
+// da migração continuam válidos até expirarem
+func NewJWTServiceWithKeys(keySet *KeySet, legacySecret string, expiresIn, refreshExpiry time.Duration) *JWTService {
+	s := &JWTService{
+		keySet:        keySet,
+		expiresIn:     expiresIn,
+		refreshExpiry: refreshExpiry,
+	}
+	if legacySecret != "" {
+		s.keys = NewJWTService(legacySecret, expiresIn, refreshExpiry).keys
 	}
+	return s
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString([]byte(s.secretKey))
+// NewJWTServiceFromConfig cria o serviço a partir da configuração: com chaves
+// configuradas usa assinatura assimétrica; caso contrário, HS256 com o segredo
+func NewJWTServiceFromConfig(cfg config.JWTConfig) (*JWTService, error) {
+	if len(cfg.SigningKeys) == 0 {
+		return NewJWTService(cfg.Secret, cfg.ExpiresIn, cfg.RefreshToken), nil
+	}
+
+	keys := make([]*SigningKey, 0, len(cfg.SigningKeys))
+	for _, file := range cfg.SigningKeys {
+		key, err := LoadSigningKey(file.ID, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	keySet, err := NewKeySet(cfg.ActiveKey, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var legacySecret string
+	if cfg.AcceptHS256 {
+		legacySecret = cfg.Secret
+	}
+	return NewJWTServiceWithKeys(keySet, legacySecret, cfg.ExpiresIn, cfg.RefreshToken), nil
+}
+
+func deriveKey(secretKey string, tokenType TokenType) []byte {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(tokenType))
+	return mac.Sum(nil)
+}
+
+func (s *JWTService) GenerateToken(userID string, opts ...TokenOption) (string, string, error) {
+	// Token de acesso
+	accessToken, err := s.sign(userID, TokenTypeAccess, s.expiresIn, opts)
 	if err != nil {
 		return "", "", err
 	}
 
 	// Token de refresh
-	refreshClaims := &Claims{
+	refreshToken, err := s.sign(userID, TokenTypeRefresh, s.refreshExpiry, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// GenerateMFAToken gera o token intermediário de curta duração do login com 2FA
+func (s *JWTService) GenerateMFAToken(userID string) (string, error) {
+	return s.sign(userID, TokenTypeMFA, mfaTokenExpiry, nil)
+}
+
+func (s *JWTService) sign(userID string, tokenType TokenType, expiry time.Duration, opts []TokenOption) (string, error) {
+	claims := &Claims{
 		UserID: userID,
+		Type:   tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.refreshExpiry)),
+			ID:        uuid.New().String(),
+			Audience:  jwt.ClaimStrings{string(tokenType)},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	for _, opt := range opts {
+		opt(claims)
+	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(s.secretKey))
-	if err != nil {
-		return "", "", err
+	if s.keySet != nil {
+		// O tipo do token é garantido pela audiência; o kid identifica a chave
+		key := s.keySet.active
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.Private)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = string(tokenType)
+	return token.SignedString(s.keys[tokenType])
+}
+
+// JWKS retorna as chaves públicas usadas na validação dos tokens. No modo
+// HS256 não há chaves públicas e o conjunto é vazio
+func (s *JWTService) JWKS() JWKSet {
+	if s.keySet == nil {
+		return JWKSet{Keys: []JWK{}}
 	}
+	return s.keySet.JWKS()
+}
 
-	return accessToken, refreshTokenString, nil
+// AccessExpiry retorna a validade configurada para os tokens de acesso
+func (s *JWTService) AccessExpiry() time.Duration {
+	return s.expiresIn
 }
 
-func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+// RefreshExpiry retorna a validade configurada para os refresh tokens
+func (s *JWTService) RefreshExpiry() time.Duration {
+	return s.refreshExpiry
+}
+
+// ValidateToken valida o token e garante que ele é do tipo esperado
+func (s *JWTService) ValidateToken(tokenString string, expected TokenType) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		if s.keySet != nil {
+			if key, ok := s.keySet.keys[kid]; ok {
+				// O algoritmo precisa ser o da chave, evitando confusão de algoritmos
+				if token.Method.Alg() != key.Method.Alg() {
+					return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+				}
+				return key.Public, nil
+			}
+		}
+
+		if s.keys == nil {
+			return nil, fmt.Errorf("chave desconhecida: %v", token.Header["kid"])
+		}
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
 		}
-		return []byte(s.secretKey), nil
-	})
+		if kid != string(expected) {
+			return nil, fmt.Errorf("tipo de token inesperado: %v", token.Header["kid"])
+		}
+		return s.keys[expected], nil
+	}, jwt.WithAudience(string(expected)))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.Type == expected {
 		return claims, nil
 	}
 
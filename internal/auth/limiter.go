@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"expenseapi/internal/model"
+)
+
+// AttemptStore é a persistência dos contadores de falhas de login
+type AttemptStore interface {
+	Get(ctx context.Context, key string) (*model.LoginAttempt, error)
+	// Increment soma uma falha à chave, recomeçando a contagem se a última
+	// falha for anterior a since, e retorna o total atualizado
+	Increment(ctx context.Context, key string, since time.Time) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+	RecordEvent(ctx context.Context, event model.LoginEvent) error
+	Purge(ctx context.Context, before time.Time) error
+}
+
+// LimitPolicy define quantas falhas são toleradas e por quanto tempo bloquear
+type LimitPolicy struct {
+	// Threshold é a quantidade de falhas permitidas antes do primeiro bloqueio
+	Threshold int
+	// BaseDelay é o bloqueio aplicado na primeira falha acima do limite; cada
+	// falha seguinte dobra o tempo, até MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window é o tempo após o qual falhas antigas são esquecidas
+	Window time.Duration
+}
+
+// lockFor calcula o bloqueio para o total de falhas informado
+func (p LimitPolicy) lockFor(failures int) time.Duration {
+	if failures <= p.Threshold {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.Threshold + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// LoginLimiter aplica backoff exponencial e bloqueio temporário às tentativas
+// de login, por conta e por IP de origem
+type LoginLimiter struct {
+	store   AttemptStore
+	account LimitPolicy
+	ip      LimitPolicy
+}
+
+// NewLoginLimiter cria um novo limitador de tentativas de login
+func NewLoginLimiter(store AttemptStore, account, ip LimitPolicy) *LoginLimiter {
+	return &LoginLimiter{store: store, account: account, ip: ip}
+}
+
+func accountKey(email string) string { return "account:" + email }
+func ipKey(ip string) string         { return "ip:" + ip }
+func tokenKey(jti string) string     { return "token:" + jti }
+
+// Check retorna quanto tempo falta para a conta ou o IP poderem tentar
+// novamente; zero indica que a tentativa é permitida
+func (l *LoginLimiter) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	var retryAfter time.Duration
+	now := time.Now()
+
+	for _, key := range l.keys(email, ip) {
+		attempt, err := l.store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if attempt != nil && attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			if wait := attempt.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// Failure registra uma falha para a conta e o IP, bloqueando-os se necessário
+func (l *LoginLimiter) Failure(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	for _, key := range l.keys(email, ip) {
+		policy := l.account
+		if key == ipKey(ip) {
+			policy = l.ip
+		}
+
+		failures, err := l.store.Increment(ctx, key, now.Add(-policy.Window))
+		if err != nil {
+			return err
+		}
+		if delay := policy.lockFor(failures); delay > 0 {
+			if err := l.store.Lock(ctx, key, now.Add(delay)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Success zera o contador da conta. O contador do IP é mantido para que um
+// atacante não possa zerá-lo entrando periodicamente na própria conta
+func (l *LoginLimiter) Success(ctx context.Context, email string) error {
+	return l.store.Reset(ctx, accountKey(email))
+}
+
+// TokenFailures retorna quantas falhas o token intermediário de login (2FA)
+// identificado pelo jti já acumulou
+func (l *LoginLimiter) TokenFailures(ctx context.Context, jti string) (int, error) {
+	attempt, err := l.store.Get(ctx, tokenKey(jti))
+	if err != nil || attempt == nil {
+		return 0, err
+	}
+	return attempt.Failures, nil
+}
+
+// TokenFailure registra uma falha para o token intermediário de login. O
+// contador fica no mesmo armazenamento das demais chaves, valendo para todas
+// as instâncias
+func (l *LoginLimiter) TokenFailure(ctx context.Context, jti string) error {
+	_, err := l.store.Increment(ctx, tokenKey(jti), time.Now().Add(-l.account.Window))
+	return err
+}
+
+// Record registra o evento de login para auditoria
+func (l *LoginLimiter) Record(ctx context.Context, event model.LoginEvent) {
+	event.CreatedAt = time.Now()
+	if err := l.store.RecordEvent(ctx, event); err != nil {
+		log.Printf("Erro ao registrar evento de login: %v", err)
+	}
+}
+
+// Start remove periodicamente os contadores antigos até o contexto ser cancelado
+func (l *LoginLimiter) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	window := l.account.Window
+	if l.ip.Window > window {
+		window = l.ip.Window
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.store.Purge(ctx, time.Now().Add(-window)); err != nil {
+				log.Printf("Erro ao remover tentativas de login antigas: %v", err)
+			}
+		}
+	}
+}
+
+func (l *LoginLimiter) keys(email, ip string) []string {
+	keys := []string{accountKey(email)}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+// MemoryAttemptStore mantém os contadores em memória, para uma única instância
+type MemoryAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*model.LoginAttempt
+	events   []model.LoginEvent
+}
+
+// maxMemoryEvents limita os eventos mantidos pelo MemoryAttemptStore
+const maxMemoryEvents = 1000
+
+// NewMemoryAttemptStore cria um novo armazenamento em memória
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]*model.LoginAttempt)}
+}
+
+func (s *MemoryAttemptStore) Get(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.attempts[key]; ok {
+		copied := *attempt
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryAttemptStore) Increment(ctx context.Context, key string, since time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok || attempt.UpdatedAt.Before(since) {
+		attempt = &model.LoginAttempt{Key: key}
+		s.attempts[key] = attempt
+	}
+	attempt.Failures++
+	attempt.UpdatedAt = time.Now()
+	return attempt.Failures, nil
+}
+
+func (s *MemoryAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.attempts[key]; ok {
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *MemoryAttemptStore) RecordEvent(ctx context.Context, event model.LoginEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	if len(s.events) > maxMemoryEvents {
+		s.events = s.events[len(s.events)-maxMemoryEvents:]
+	}
+	return nil
+}
+
+// Events retorna os eventos registrados mais recentes
+func (s *MemoryAttemptStore) Events() []model.LoginEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.LoginEvent(nil), s.events...)
+}
+
+func (s *MemoryAttemptStore) Purge(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, attempt := range s.attempts {
+		locked := attempt.LockedUntil != nil && attempt.LockedUntil.After(now)
+		if attempt.UpdatedAt.Before(before) && !locked {
+			delete(s.attempts, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"expenseapi/internal/model"
+)
+
+// RevocationStore é a persistência usada pela lista de revogação
+type RevocationStore interface {
+	RevokeToken(ctx context.Context, token model.RevokedToken) error
+	RevokeUser(ctx context.Context, revocation model.UserRevocation) error
+	RevokeSession(ctx context.Context, session model.RevokedSession) error
+	ListActive(ctx context.Context, now time.Time) ([]model.RevokedToken, []model.UserRevocation, []model.RevokedSession, error)
+	PurgeExpired(ctx context.Context, now time.Time) error
+}
+
+// RevocationList mantém em memória os tokens revogados, espelhando o banco de
+// dados, para que o middleware não precise consultá-lo a cada requisição
+type RevocationList struct {
+	store    RevocationStore
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	users    map[string]model.UserRevocation
+	sessions map[string]time.Time
+}
+
+// NewRevocationList cria uma nova lista de revogação vazia
+func NewRevocationList(store RevocationStore) *RevocationList {
+	return &RevocationList{
+		store:    store,
+		tokens:   make(map[string]time.Time),
+		users:    make(map[string]model.UserRevocation),
+		sessions: make(map[string]time.Time),
+	}
+}
+
+// RevokeToken revoga um único token até a sua expiração
+func (l *RevocationList) RevokeToken(ctx context.Context, claims *Claims) error {
+	revoked := model.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if err := l.store.RevokeToken(ctx, revoked); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.tokens[revoked.JTI] = revoked.ExpiresAt
+	l.mu.Unlock()
+	return nil
+}
+
+// RevokeUser revoga todos os tokens do usuário emitidos antes de before. A
+// entrada expira após ttl, quando nenhum desses tokens pode mais ser válido
+func (l *RevocationList) RevokeUser(ctx context.Context, userID string, before time.Time, ttl time.Duration) error {
+	// O iat tem precisão de segundos: truncando o corte, tokens emitidos logo
+	// após a revogação (no mesmo segundo) continuam válidos. Os emitidos antes
+	// dela no mesmo segundo são cobertos pela revogação das sessões
+	before = before.Truncate(time.Second)
+
+	revocation := model.UserRevocation{
+		UserID:        userID,
+		RevokedBefore: before,
+		ExpiresAt:     before.Add(ttl),
+	}
+	if err := l.store.RevokeUser(ctx, revocation); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.users[userID] = revocation
+	l.mu.Unlock()
+	return nil
+}
+
+// RevokeSession revoga os tokens de acesso de uma sessão. A entrada expira
+// após ttl, quando nenhum token emitido para a sessão pode mais ser válido
+func (l *RevocationList) RevokeSession(ctx context.Context, sessionID, userID string, ttl time.Duration) error {
+	revoked := model.RevokedSession{
+		SessionID: sessionID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := l.store.RevokeSession(ctx, revoked); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.sessions[sessionID] = revoked.ExpiresAt
+	l.mu.Unlock()
+	return nil
+}
+
+// IsRevoked indica se o token foi revogado
+func (l *RevocationList) IsRevoked(claims *Claims) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if _, ok := l.tokens[claims.ID]; ok {
+		return true
+	}
+
+	if claims.SessionID != "" {
+		if _, ok := l.sessions[claims.SessionID]; ok {
+			return true
+		}
+	}
+
+	if revocation, ok := l.users[claims.UserID]; ok && claims.IssuedAt != nil {
+		return claims.IssuedAt.Time.Before(revocation.RevokedBefore)
+	}
+
+	return false
+}
+
+// Sync remove as entradas expiradas e recarrega a lista a partir do banco,
+// incorporando revogações feitas por outras instâncias
+func (l *RevocationList) Sync(ctx context.Context) error {
+	now := time.Now()
+	if err := l.store.PurgeExpired(ctx, now); err != nil {
+		return err
+	}
+
+	tokens, users, sessions, err := l.store.ListActive(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	tokenMap := make(map[string]time.Time, len(tokens))
+	for _, token := range tokens {
+		tokenMap[token.JTI] = token.ExpiresAt
+	}
+	userMap := make(map[string]model.UserRevocation, len(users))
+	for _, user := range users {
+		userMap[user.UserID] = user
+	}
+	sessionMap := make(map[string]time.Time, len(sessions))
+	for _, session := range sessions {
+		sessionMap[session.SessionID] = session.ExpiresAt
+	}
+
+	l.mu.Lock()
+	l.tokens = tokenMap
+	l.users = userMap
+	l.sessions = sessionMap
+	l.mu.Unlock()
+	return nil
+}
+
+// Start sincroniza a lista periodicamente até o contexto ser cancelado
+func (l *RevocationList) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Sync(ctx); err != nil {
+				log.Printf("Erro ao sincronizar lista de revogação: %v", err)
+			}
+		}
+	}
+}
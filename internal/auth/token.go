@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// HashToken retorna o hash SHA-256 (hex) de um token, usado para persisti-lo
+// sem armazenar o valor original
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateOpaqueToken gera um token aleatório de 256 bits codificado em base64 (URL-safe)
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// PersonalTokenPrefix identifica os tokens de acesso pessoal, permitindo
+// diferenciá-los de um JWT sem consultar o banco
+const PersonalTokenPrefix = "eat_"
+
+// Escopos aceitos pelos tokens de acesso pessoal
+const (
+	ScopeExpensesRead  = "expenses:read"
+	ScopeExpensesWrite = "expenses:write"
+)
+
+// PersonalTokenScopes lista os escopos que podem ser concedidos a um token pessoal
+var PersonalTokenScopes = []string{ScopeExpensesRead, ScopeExpensesWrite}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,6 +14,13 @@ type Config struct {
 	DB     DBConfig
 	JWT    JWTConfig
 	Server ServerConfig
+	App    AppConfig
+	Auth   AuthConfig
+	Mail   MailConfig
+	// Currency contém as fontes das cotações de moedas
+	Currency CurrencyConfig
+	// OIDC lista os provedores de login único habilitados
+	OIDC []OIDCProviderConfig
 }
 
 type DBConfig struct {
@@ -28,10 +36,106 @@ type JWTConfig struct {
 	Secret       string
 	ExpiresIn    time.Duration
 	RefreshToken time.Duration
+	// SigningKeys são as chaves RSA/Ed25519 em PEM; quando definidas, substituem
+	// o segredo HS256. ActiveKey é o kid usado para assinar novos tokens
+	SigningKeys []KeyFile
+	ActiveKey   string
+	// AcceptHS256 mantém válidos os tokens assinados com o segredo, durante a
+	// migração para chaves assimétricas
+	AcceptHS256 bool
+}
+
+// KeyFile associa um kid ao arquivo PEM da chave
+type KeyFile struct {
+	ID   string
+	Path string
 }
 
 type ServerConfig struct {
 	Port string
+	// TrustProxy indica que o IP do cliente deve ser lido do header X-Forwarded-For
+	TrustProxy bool
+	// TrustedProxies são os endereços ou redes (CIDR) dos proxies confiáveis.
+	// Vazio, apenas o proxy imediato é considerado
+	TrustedProxies []string
+}
+
+// AppConfig contém dados da aplicação cliente, usados nos links enviados por email
+type AppConfig struct {
+	Name string
+	URL  string
+}
+
+// AuthConfig contém as configurações dos fluxos de autenticação
+type AuthConfig struct {
+	PasswordResetTTL time.Duration
+	EmailTokenTTL    time.Duration
+	// UnverifiedAccess define o acesso de usuários com email não verificado:
+	// "allow" (completo), "read_only" (apenas leitura) ou "deny" (sem login)
+	UnverifiedAccess string
+	Login            LoginLimitConfig
+	// DeletionGrace é o prazo entre o pedido de exclusão da conta e a exclusão
+	// definitiva, durante o qual um novo login cancela o pedido
+	DeletionGrace  time.Duration
+	PasswordHash   PasswordHashConfig
+	PasswordPolicy PasswordPolicyConfig
+}
+
+// PasswordPolicyConfig define as regras aplicadas às novas senhas
+type PasswordPolicyConfig struct {
+	MinLength int
+	// RequiredClasses são as classes de caracteres exigidas: lower, upper, digit e symbol
+	RequiredClasses []string
+	// BreachedList é o arquivo com os hashes SHA-1 de senhas vazadas (opcional)
+	BreachedList string
+}
+
+// PasswordHashConfig contém os parâmetros do Argon2id usados nos novos hashes
+// de senha. Hashes com parâmetros menores são atualizados no próximo login
+type PasswordHashConfig struct {
+	// Memory é a memória usada por hash, em KiB
+	Memory      int
+	Iterations  int
+	Parallelism int
+}
+
+// LoginLimitConfig contém os limites de tentativas de login por conta e por IP
+type LoginLimitConfig struct {
+	// Store define onde os contadores ficam: "postgres" ou "memory"
+	Store         string
+	MaxAttempts   int
+	IPMaxAttempts int
+	LockoutBase   time.Duration
+	LockoutMax    time.Duration
+	Window        time.Duration
+}
+
+// OIDCProviderConfig contém os dados de um provedor OpenID Connect
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// CurrencyConfig contém as configurações de conversão de moedas
+type CurrencyConfig struct {
+	// RateFiles são arquivos de cotações (CSV ou XML do Banco Central Europeu)
+	// importados na inicialização
+	RateFiles []string
+}
+
+// MailConfig contém as configurações de envio de emails
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	OutputDir    string
 }
 
 func (c *DBConfig) DSN() string {
@@ -48,6 +152,12 @@ func New() *Config {
 
 	expiresIn, _ := strconv.Atoi(getEnv("JWT_EXPIRES_IN", "3600"))
 	refreshToken, _ := strconv.Atoi(getEnv("JWT_REFRESH_TOKEN", "604800"))
+	passwordResetTTL, _ := strconv.Atoi(getEnv("PASSWORD_RESET_TTL", "3600"))
+	emailTokenTTL, _ := strconv.Atoi(getEnv("EMAIL_TOKEN_TTL", "86400"))
+	lockoutBase, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_BASE", "30"))
+	lockoutMax, _ := strconv.Atoi(getEnv("LOGIN_LOCKOUT_MAX", "900"))
+	attemptWindow, _ := strconv.Atoi(getEnv("LOGIN_ATTEMPT_WINDOW", "3600"))
+	deletionGrace, _ := strconv.Atoi(getEnv("ACCOUNT_DELETION_GRACE", "2592000"))
 
 	return &Config{
 		DB: DBConfig{
@@ -62,13 +172,106 @@ func New() *Config {
 			Secret:       getEnv("JWT_SECRET", "seu_segredo_jwt"),
 			ExpiresIn:    time.Duration(expiresIn) * time.Second,
 			RefreshToken: time.Duration(refreshToken) * time.Second,
+			SigningKeys:  parseKeyFiles(getEnv("JWT_SIGNING_KEYS", "")),
+			ActiveKey:    getEnv("JWT_ACTIVE_KEY", ""),
+			AcceptHS256:  getEnv("JWT_ACCEPT_HS256", "false") == "true",
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8081"),
+			Port:           getEnv("PORT", "8081"),
+			TrustProxy:     getEnv("TRUST_PROXY", "false") == "true",
+			TrustedProxies: parseList(getEnv("TRUSTED_PROXIES", "")),
+		},
+		App: AppConfig{
+			Name: getEnv("APP_NAME", "Expense API"),
+			URL:  getEnv("APP_URL", "http://localhost:3000"),
+		},
+		Auth: AuthConfig{
+			PasswordResetTTL: time.Duration(passwordResetTTL) * time.Second,
+			EmailTokenTTL:    time.Duration(emailTokenTTL) * time.Second,
+			UnverifiedAccess: getEnv("UNVERIFIED_USER_ACCESS", "allow"),
+			Login: LoginLimitConfig{
+				Store:         getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+				MaxAttempts:   getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+				IPMaxAttempts: getEnvInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+				LockoutBase:   time.Duration(lockoutBase) * time.Second,
+				LockoutMax:    time.Duration(lockoutMax) * time.Second,
+				Window:        time.Duration(attemptWindow) * time.Second,
+			},
+			DeletionGrace:  time.Duration(deletionGrace) * time.Second,
+			PasswordHash:   newPasswordHashConfig(),
+			PasswordPolicy: newPasswordPolicyConfig(),
 		},
+		Mail:     newMailConfig(),
+		Currency: newCurrencyConfig(),
+		OIDC:     newOIDCConfig(),
+	}
+}
+
+// Validate confere se os parâmetros do Argon2id estão nos limites do algoritmo
+func (c PasswordHashConfig) Validate() error {
+	if c.Iterations < 1 || c.Parallelism < 1 || c.Parallelism > 255 || c.Memory < 8*c.Parallelism {
+		return fmt.Errorf("parâmetros do Argon2id inválidos (PASSWORD_ARGON2_*)")
+	}
+	return nil
+}
+
+func newPasswordHashConfig() PasswordHashConfig {
+	return PasswordHashConfig{
+		Memory:      getEnvInt("PASSWORD_ARGON2_MEMORY", 64*1024),
+		Iterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 3),
+		Parallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 2),
 	}
 }
 
+func newPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:       getEnvInt("PASSWORD_MIN_LENGTH", 6),
+		RequiredClasses: parseList(getEnv("PASSWORD_REQUIRED_CLASSES", "")),
+		BreachedList:    getEnv("PASSWORD_BREACHED_LIST", ""),
+	}
+}
+
+func newMailConfig() MailConfig {
+	return MailConfig{
+		Driver:       getEnv("MAIL_DRIVER", "log"),
+		From:         getEnv("MAIL_FROM", "no-reply@expenseapi.local"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "25"),
+		SMTPUser:     getEnv("SMTP_USER", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		OutputDir:    getEnv("MAIL_OUTPUT_DIR", ""),
+	}
+}
+
+// newCurrencyConfig lê os arquivos de cotações listados em EXCHANGE_RATE_FILES
+func newCurrencyConfig() CurrencyConfig {
+	return CurrencyConfig{RateFiles: parseList(getEnv("EXCHANGE_RATE_FILES", ""))}
+}
+
+// newOIDCConfig lê os provedores listados em OIDC_PROVIDERS. Cada provedor
+// "nome" é configurado pelas variáveis OIDC_<NOME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL e _SCOPES
+func newOIDCConfig() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", "http://localhost:8081/api/v1/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+	return providers
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -76,6 +279,14 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // Load carrega as configurações do ambiente
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
@@ -92,13 +303,41 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8081"),
+			Port:           getEnv("PORT", "8081"),
+			TrustProxy:     getEnv("TRUST_PROXY", "false") == "true",
+			TrustedProxies: parseList(getEnv("TRUSTED_PROXIES", "")),
 		},
 		JWT: JWTConfig{
 			Secret:       getEnv("JWT_SECRET", "seu_secret_muito_secreto"),
 			ExpiresIn:    parseDuration(getEnv("JWT_EXPIRES_IN", "24h")),
 			RefreshToken: parseDuration(getEnv("JWT_REFRESH_TOKEN_EXPIRES", "168h")),
+			SigningKeys:  parseKeyFiles(getEnv("JWT_SIGNING_KEYS", "")),
+			ActiveKey:    getEnv("JWT_ACTIVE_KEY", ""),
+			AcceptHS256:  getEnv("JWT_ACCEPT_HS256", "false") == "true",
+		},
+		App: AppConfig{
+			Name: getEnv("APP_NAME", "Expense API"),
+			URL:  getEnv("APP_URL", "http://localhost:3000"),
 		},
+		Auth: AuthConfig{
+			PasswordResetTTL: parseDuration(getEnv("PASSWORD_RESET_TTL", "1h")),
+			EmailTokenTTL:    parseDuration(getEnv("EMAIL_TOKEN_TTL", "24h")),
+			UnverifiedAccess: getEnv("UNVERIFIED_USER_ACCESS", "allow"),
+			Login: LoginLimitConfig{
+				Store:         getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+				MaxAttempts:   getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+				IPMaxAttempts: getEnvInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+				LockoutBase:   parseDuration(getEnv("LOGIN_LOCKOUT_BASE", "30s")),
+				LockoutMax:    parseDuration(getEnv("LOGIN_LOCKOUT_MAX", "15m")),
+				Window:        parseDuration(getEnv("LOGIN_ATTEMPT_WINDOW", "1h")),
+			},
+			DeletionGrace:  parseDuration(getEnv("ACCOUNT_DELETION_GRACE", "720h")),
+			PasswordHash:   newPasswordHashConfig(),
+			PasswordPolicy: newPasswordPolicyConfig(),
+		},
+		Mail:     newMailConfig(),
+		Currency: newCurrencyConfig(),
+		OIDC:     newOIDCConfig(),
 	}
 
 	// Valores padrão
@@ -125,13 +364,43 @@ func Load() (*Config, error) {
 	if config.DB.SSLMode == "" {
 		return nil, fmt.Errorf("DB_SSL_MODE não definida")
 	}
-	if config.JWT.Secret == "" {
+	if config.JWT.Secret == "" && len(config.JWT.SigningKeys) == 0 {
 		return nil, fmt.Errorf("JWT_SECRET não definida")
 	}
+	if len(config.JWT.SigningKeys) > 0 && config.JWT.ActiveKey == "" {
+		return nil, fmt.Errorf("JWT_ACTIVE_KEY não definida")
+	}
+	if err := config.Auth.PasswordHash.Validate(); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
 
+// parseList separa uma lista de valores separados por vírgula, ignorando os vazios
+func parseList(value string) []string {
+	var values []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
+
+// parseKeyFiles interpreta a lista "kid1=/caminho/a.pem,kid2=/caminho/b.pem"
+func parseKeyFiles(value string) []KeyFile {
+	var files []KeyFile
+	for _, entry := range strings.Split(value, ",") {
+		id, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || id == "" || path == "" {
+			continue
+		}
+		files = append(files, KeyFile{ID: id, Path: path})
+	}
+	return files
+}
+
 func parseDuration(value string) time.Duration {
 	duration, err := time.ParseDuration(value)
 	if err != nil {
@@ -0,0 +1,81 @@
+package currency
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"expenseapi/internal/model"
+)
+
+// ParseCSV lê cotações no formato "date,currency,rate" (uma por linha, com a
+// data em YYYY-MM-DD). A primeira linha pode ser o cabeçalho
+func ParseCSV(r io.Reader) ([]model.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var rates []model.ExchangeRate
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", record[0])
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: data inválida", line)
+		}
+		rate, err := NewRate(date, record[1], record[2])
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// ecbEnvelope é o formato dos arquivos de referência do Banco Central Europeu
+// (eurofxref-daily.xml e eurofxref-hist.xml)
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB lê as cotações de um arquivo XML do Banco Central Europeu
+func ParseECB(r io.Reader) ([]model.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	var rates []model.ExchangeRate
+	for _, d := range envelope.Days {
+		date, err := time.Parse("2006-01-02", d.Time)
+		if err != nil {
+			return nil, fmt.Errorf("data inválida: %q", d.Time)
+		}
+		for _, r := range d.Rates {
+			rate, err := NewRate(date, r.Currency, r.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", d.Time, r.Currency, err)
+			}
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
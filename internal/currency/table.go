@@ -0,0 +1,194 @@
+package currency
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"expenseapi/internal/model"
+)
+
+// Reference é a moeda de referência das cotações: cada cotação informa quantas
+// unidades da moeda valem 1 EUR, como nas tabelas do Banco Central Europeu
+const Reference = "EUR"
+
+// Default é a moeda assumida quando nenhuma outra é informada
+const Default = "BRL"
+
+var (
+	ErrInvalidCode  = errors.New("código de moeda inválido (use o padrão ISO 4217, como USD)")
+	ErrInvalidRate  = errors.New("cotação inválida")
+	ErrRateNotFound = errors.New("cotação não encontrada")
+)
+
+var codeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCode converte o código para maiúsculas e confere o formato ISO 4217
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !codeRegex.MatchString(code) {
+		return "", ErrInvalidCode
+	}
+	return code, nil
+}
+
+// NewRate valida e normaliza uma cotação. O valor é um decimal positivo, em
+// unidades da moeda por 1 EUR
+func NewRate(date time.Time, code, value string) (model.ExchangeRate, error) {
+	code, err := NormalizeCode(code)
+	if err != nil {
+		return model.ExchangeRate{}, err
+	}
+	if code == Reference {
+		return model.ExchangeRate{}, ErrInvalidRate
+	}
+	rate, ok := parseRate(value)
+	if !ok {
+		return model.ExchangeRate{}, ErrInvalidRate
+	}
+	return model.ExchangeRate{
+		Date:     day(date),
+		Currency: code,
+		Rate:     strings.TrimRight(strings.TrimRight(rate.FloatString(10), "0"), "."),
+	}, nil
+}
+
+func parseRate(value string) (*big.Rat, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.ContainsAny(value, "eE/") {
+		return nil, false
+	}
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, false
+	}
+	return rate, true
+}
+
+// day descarta o horário, mantendo apenas a data em UTC
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+type point struct {
+	date time.Time
+	rate *big.Rat
+}
+
+// Table guarda em memória o histórico de cotações de cada moeda, ordenado por data
+type Table struct {
+	mu    sync.RWMutex
+	rates map[string][]point
+}
+
+// NewTable cria uma tabela de cotações vazia
+func NewTable() *Table {
+	return &Table{rates: make(map[string][]point)}
+}
+
+// Add inclui as cotações na tabela. Uma cotação da mesma moeda e data substitui
+// a anterior
+func (t *Table) Add(rates ...model.ExchangeRate) error {
+	parsed := make(map[string][]point)
+	for _, rate := range rates {
+		value, ok := parseRate(rate.Rate)
+		if !ok {
+			return ErrInvalidRate
+		}
+		parsed[rate.Currency] = append(parsed[rate.Currency], point{date: day(rate.Date), rate: value})
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for code, points := range parsed {
+		// As cotações novas vêm depois das existentes para prevalecerem na
+		// remoção das datas repetidas
+		merged := append(append([]point{}, t.rates[code]...), points...)
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].date.Before(merged[j].date) })
+
+		unique := merged[:0]
+		for _, p := range merged {
+			if n := len(unique); n > 0 && unique[n-1].date.Equal(p.date) {
+				unique[n-1] = p
+				continue
+			}
+			unique = append(unique, p)
+		}
+		t.rates[code] = unique
+	}
+	return nil
+}
+
+// Has indica se a moeda pode ser convertida, ou seja, se é a moeda de
+// referência ou tem ao menos uma cotação
+func (t *Table) Has(code string) bool {
+	if code == Reference {
+		return true
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.rates[code]) > 0
+}
+
+// Rate retorna a cotação da moeda na data. Em dias sem cotação (fins de semana
+// e feriados) vale a última cotação anterior
+func (t *Table) Rate(code string, date time.Time) (*big.Rat, error) {
+	if code == Reference {
+		return big.NewRat(1, 1), nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	points := t.rates[code]
+	date = day(date)
+	i := sort.Search(len(points), func(i int) bool { return points[i].date.After(date) })
+	if i == 0 {
+		return nil, ErrRateNotFound
+	}
+	return points[i-1].rate, nil
+}
+
+// Convert converte o valor entre duas moedas com as cotações da data. O cálculo
+// é exato e o resultado é arredondado para o centavo mais próximo (metades para
+// longe do zero)
+func (t *Table) Convert(amount model.Money, from, to string, date time.Time) (model.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	fromRate, err := t.Rate(from, date)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := t.Rate(to, date)
+	if err != nil {
+		return 0, err
+	}
+
+	// valor * (destino / origem), ambos em unidades por 1 EUR
+	result := new(big.Rat).SetInt64(amount.Cents())
+	result.Mul(result, toRate)
+	result.Quo(result, fromRate)
+
+	return model.MoneyFromCents(roundHalfAwayFromZero(result)), nil
+}
+
+func roundHalfAwayFromZero(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return quo.Int64()
+}
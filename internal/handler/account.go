@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// AccountHandler gerencia as requisições HTTP da conta do usuário autenticado
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+// NewAccountHandler cria uma nova instância do handler de conta
+func NewAccountHandler(service *service.AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+// Profile retorna o perfil do usuário autenticado
+func (h *AccountHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.service.Profile(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			writeMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// ChangePassword troca a senha do usuário autenticado
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.ChangePasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.service.ChangePassword(r.Context(), userID, input, middleware.ClientInfo(r))
+	if err != nil {
+		if writePasswordPolicyError(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			writeMessage(w, http.StatusUnauthorized, "senha atual incorreta")
+		case errors.Is(err, service.ErrUserNotFound):
+			writeMessage(w, http.StatusNotFound, err.Error())
+		default:
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// ChangeEmail solicita a troca de email, enviando a confirmação ao novo endereço
+func (h *AccountHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.ChangeEmailInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	// Validação do email
+	if !emailRegex.MatchString(input.Email) {
+		writeMessage(w, http.StatusBadRequest, "email inválido")
+		return
+	}
+
+	err := h.service.RequestEmailChange(r.Context(), userID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			writeMessage(w, http.StatusUnauthorized, "senha incorreta")
+		case errors.Is(err, service.ErrSameEmail):
+			writeMessage(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrUserExists):
+			writeMessage(w, http.StatusConflict, "email já está em uso")
+		case errors.Is(err, service.ErrUserNotFound):
+			writeMessage(w, http.StatusNotFound, err.Error())
+		default:
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeMessage(w, http.StatusAccepted, "enviamos um link de confirmação para o novo email")
+}
+
+// ConfirmEmail confirma a troca de email com o token recebido no novo endereço
+func (h *AccountHandler) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	var input model.ConfirmEmailInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if input.Token == "" {
+		writeMessage(w, http.StatusBadRequest, "token não fornecido")
+		return
+	}
+
+	user, err := h.service.ConfirmEmailChange(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidEmailToken):
+			writeMessage(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrUserExists):
+			writeMessage(w, http.StatusConflict, "email já está em uso")
+		default:
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
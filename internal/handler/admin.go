@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
+)
+
+// AdminHandler gerencia as requisições HTTP da API administrativa
+type AdminHandler struct {
+	service *service.AdminService
+}
+
+// NewAdminHandler cria uma nova instância do handler administrativo
+func NewAdminHandler(service *service.AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+// ListUsers lista os usuários, paginados por limit e offset
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := defaultUserPageSize, 0
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxUserPageSize {
+			writeMessage(w, http.StatusBadRequest, "limit deve estar entre 1 e "+strconv.Itoa(maxUserPageSize))
+			return
+		}
+		limit = parsed
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeMessage(w, http.StatusBadRequest, "offset inválido")
+			return
+		}
+		offset = parsed
+	}
+
+	users, err := h.service.ListUsers(r.Context(), limit, offset)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+// SetRole altera o papel de um usuário
+func (h *AdminHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	var input model.UpdateRoleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	adminID := middleware.GetUserIDFromContext(r.Context())
+	user, err := h.service.SetRole(r.Context(), adminID, r.PathValue("id"), input.Role)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// Disable desativa a conta de um usuário
+func (h *AdminHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	adminID := middleware.GetUserIDFromContext(r.Context())
+	if err := h.service.Disable(r.Context(), adminID, r.PathValue("id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Enable reativa a conta de um usuário
+func (h *AdminHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Enable(r.Context(), r.PathValue("id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForceLogout encerra todas as sessões de um usuário
+func (h *AdminHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.ForceLogout(r.Context(), r.PathValue("id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		writeMessage(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrCannotChangeSelf):
+		writeMessage(w, http.StatusBadRequest, err.Error())
+	default:
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+	}
+}
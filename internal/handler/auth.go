@@ -3,9 +3,12 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
 	"regexp"
+	"strconv"
 
+	"expenseapi/internal/middleware"
 	"expenseapi/internal/model"
 	"expenseapi/internal/service"
 )
@@ -40,17 +43,12 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validação da senha
-	if len(input.Password) < 6 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "senha deve ter no mínimo 6 caracteres",
-		})
-		return
-	}
-
+	// A senha é validada pela política de senhas do serviço
 	user, err := h.authService.Register(r.Context(), input)
 	if err != nil {
+		if writePasswordPolicyError(w, err) {
+			return
+		}
 		if errors.Is(err, service.ErrUserExists) {
 			w.WriteHeader(http.StatusConflict)
 			json.NewEncoder(w).Encode(map[string]string{
@@ -86,8 +84,20 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	input.IP = middleware.ClientIP(r)
+	input.UserAgent = r.UserAgent()
+
 	response, err := h.authService.Login(r.Context(), input)
 	if err != nil {
+		var rateLimit *service.RateLimitError
+		if errors.As(err, &rateLimit) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimit.RetryAfter.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{
+				"message": "muitas tentativas de login, tente novamente mais tarde",
+			})
+			return
+		}
 		if errors.Is(err, service.ErrInvalidCredentials) {
 			w.WriteHeader(http.StatusUnauthorized)
 			json.NewEncoder(w).Encode(map[string]string{
@@ -95,6 +105,20 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"message": "email não verificado",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAccountDisabled) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{
 			"message": "Erro interno do servidor",
@@ -105,3 +129,83 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var input model.RefreshTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if input.RefreshToken == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "refresh token não fornecido",
+		})
+		return
+	}
+
+	response, err := h.authService.Refresh(r.Context(), input, middleware.ClientInfo(r))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefresh) || errors.Is(err, service.ErrRefreshReused) || errors.Is(err, service.ErrAccountDisabled) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Erro interno do servidor",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaimsFromContext(r.Context())
+	if claims == nil {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	// O corpo é opcional: sem refresh token, apenas o token de acesso é revogado
+	var input model.LogoutInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.authService.Logout(r.Context(), claims, input); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Erro interno do servidor",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.LogoutAll(r.Context(), userID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Erro interno do servidor",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// CategoryHandler gerencia as requisições HTTP das categorias de despesas
+type CategoryHandler struct {
+	service *service.CategoryService
+}
+
+// NewCategoryHandler cria uma nova instância do handler de categorias
+func NewCategoryHandler(service *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: service}
+}
+
+// List lista as categorias do usuário autenticado. As arquivadas só são
+// incluídas com ?archived=true
+func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	includeArchived := r.URL.Query().Get("archived") == "true"
+	categories, err := h.service.List(r.Context(), userID, includeArchived)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// Create cria uma categoria para o usuário autenticado
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.CreateCategoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.service.Create(r.Context(), userID, input)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, category)
+}
+
+// Update altera uma categoria do usuário autenticado
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.UpdateCategoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.service.Update(r.Context(), userID, r.PathValue("id"), input)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, category)
+}
+
+// Delete remove uma categoria sem despesas do usuário autenticado
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), userID, r.PathValue("id")); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCategoryError responde com o status correspondente ao erro do serviço
+func writeCategoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategoryName),
+		errors.Is(err, service.ErrInvalidCategoryColor),
+		errors.Is(err, service.ErrInvalidCategoryIcon),
+		errors.Is(err, service.ErrCategoryParent),
+		errors.Is(err, service.ErrCategoryCycle):
+		writeMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCategoryNotFound):
+		writeMessage(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCategoryExists), errors.Is(err, service.ErrCategoryInUse):
+		writeMessage(w, http.StatusConflict, err.Error())
+	default:
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"expenseapi/internal/currency"
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// maxRatesUpload limita o tamanho dos arquivos de cotações enviados (o histórico
+// completo do Banco Central Europeu tem cerca de 6 MB)
+const maxRatesUpload = 32 << 20
+
+// CurrencyHandler gerencia as requisições HTTP de moedas e cotações
+type CurrencyHandler struct {
+	service *service.CurrencyService
+}
+
+// NewCurrencyHandler cria uma nova instância do handler de moedas
+func NewCurrencyHandler(service *service.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{service: service}
+}
+
+// ImportRates importa cotações enviadas como JSON, CSV (text/csv) ou no XML do
+// Banco Central Europeu (application/xml), conforme o Content-Type
+func (h *CurrencyHandler) ImportRates(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxRatesUpload)
+
+	rates, err := parseRates(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, "cotações inválidas: "+err.Error())
+		return
+	}
+
+	imported, err := h.service.Import(r.Context(), rates)
+	if err != nil {
+		if errors.Is(err, service.ErrNoRates) {
+			writeMessage(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ImportRatesResponse{Imported: imported})
+}
+
+func parseRates(contentType string, body io.Reader) ([]model.ExchangeRate, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return currency.ParseCSV(body)
+	case "application/xml", "text/xml":
+		return currency.ParseECB(body)
+	}
+
+	var inputs []model.ExchangeRateInput
+	if err := json.NewDecoder(body).Decode(&inputs); err != nil {
+		return nil, errors.New("erro ao decodificar requisição")
+	}
+
+	rates := make([]model.ExchangeRate, 0, len(inputs))
+	for _, input := range inputs {
+		date, err := time.Parse("2006-01-02", input.Date)
+		if err != nil {
+			return nil, errors.New("data inválida: " + input.Date)
+		}
+		rate, err := currency.NewRate(date, input.Currency, input.Rate.String())
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// SetBaseCurrency altera a moeda base do usuário autenticado
+func (h *CurrencyHandler) SetBaseCurrency(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.UpdateBaseCurrencyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.SetBaseCurrency(r.Context(), userID, input.BaseCurrency)
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidCode), errors.Is(err, service.ErrUnsupportedCurrency):
+			writeMessage(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrUserNotFound):
+			writeMessage(w, http.StatusNotFound, err.Error())
+		default:
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
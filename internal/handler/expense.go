@@ -2,9 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"expenseapi/internal/currency"
 	"expenseapi/internal/middleware"
 	"expenseapi/internal/model"
 	"expenseapi/internal/service"
@@ -30,12 +36,16 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var input model.CreateExpenseInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "dados inválidos", http.StatusBadRequest)
+		http.Error(w, invalidExpenseMessage(err), http.StatusBadRequest)
 		return
 	}
 
 	expense, err := h.service.Create(r.Context(), userID, &input)
 	if err != nil {
+		if isExpenseInputError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -69,7 +79,9 @@ func (h *ExpenseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(expense)
 }
 
-// List retorna todas as despesas do usuário
+// List retorna uma página das despesas do usuário. Aceita os filtros de
+// parseExpenseFilter e um período predefinido (period), além de limit, cursor,
+// sort e order
 func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserIDFromContext(r.Context())
 	if userID == "" {
@@ -77,48 +89,267 @@ func (h *ExpenseHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	period := r.URL.Query().Get("period")
-	var expenses []*model.Expense
-	var err error
-
-	if period != "" {
-		expenses, err = h.service.GetExpensesByPeriod(r.Context(), userID, period)
-	} else {
-		startDateStr := r.URL.Query().Get("start_date")
-		endDateStr := r.URL.Query().Get("end_date")
-		categoryStr := r.URL.Query().Get("category")
+	params := model.ExpenseListParams{
+		Cursor: r.URL.Query().Get("cursor"),
+		Sort:   r.URL.Query().Get("sort"),
+		Order:  r.URL.Query().Get("order"),
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		var err error
+		params.Limit, err = strconv.Atoi(limit)
+		if err != nil || params.Limit < 1 {
+			http.Error(w, service.ErrInvalidLimit.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
-		filter := &model.ExpenseFilter{}
+	filter, errs := parseExpenseFilter(r)
+	if errs != nil {
+		writeFilterErrors(w, errs)
+		return
+	}
+	if period := r.URL.Query().Get("period"); period != "" {
+		if !applyPeriod(filter, period) {
+			writeFilterErrors(w, filterErrors{"period": "deve ser week, month ou quarter"})
+			return
+		}
+	}
 
-		if startDateStr != "" {
-			startDate, err := time.Parse("2006-01-02", startDateStr)
-			if err == nil {
-				filter.StartDate = &startDate
-			}
+	page, err := h.service.ListPage(r.Context(), userID, filter, params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSort) || errors.Is(err, service.ErrInvalidLimit) || errors.Is(err, service.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
 
-		if endDateStr != "" {
-			endDate, err := time.Parse("2006-01-02", endDateStr)
-			if err == nil {
-				filter.EndDate = &endDate
-			}
+// Search busca as despesas do usuário pelo texto em q, na descrição, no
+// estabelecimento e nas observações. Aceita os mesmos filtros da listagem e
+// limit; q é sempre o texto da busca
+func (h *ExpenseHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			http.Error(w, service.ErrInvalidLimit.Error(), http.StatusBadRequest)
+			return
 		}
+	}
 
-		if categoryStr != "" {
-			category := model.Category(categoryStr)
-			filter.Category = &category
+	filter, errs := parseExpenseFilter(r)
+	if errs != nil {
+		writeFilterErrors(w, errs)
+		return
+	}
+	text := filter.Query
+	filter.Query = ""
+
+	results, err := h.service.Search(r.Context(), userID, text, filter, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptySearch) || errors.Is(err, service.ErrInvalidLimit) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// Summary retorna o total das despesas do usuário, geral e por categoria
+func (h *ExpenseHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
 
-		expenses, err = h.service.List(r.Context(), userID, filter)
+	filter, errs := parseExpenseFilter(r)
+	if errs != nil {
+		writeFilterErrors(w, errs)
+		return
 	}
 
+	summary, err := h.service.Summary(r.Context(), userID, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(expenses)
+	json.NewEncoder(w).Encode(summary)
+}
+
+// maxFilterQueryLength é o tamanho máximo do texto buscado na descrição, o
+// mesmo da própria descrição
+const maxFilterQueryLength = 255
+
+// filterErrors associa cada parâmetro de filtro inválido ao motivo
+type filterErrors map[string]string
+
+// writeFilterErrors responde 400 com os parâmetros de filtro inválidos
+func writeFilterErrors(w http.ResponseWriter, errs filterErrors) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"message": "filtros inválidos",
+		"fields":  errs,
+	})
+}
+
+// parseExpenseFilter lê os filtros da query string: período (start_date e
+// end_date), valor (min_amount e max_amount), texto na descrição (q),
+// categorias, criação e alteração (created_after e updated_after) e tags.
+// category e tag podem ser repetidos; com várias tags (?tag=a&tag=b), basta uma
+// delas, ou todas com tag_match=all. Parâmetros que não puderem ser lidos são
+// retornados em filterErrors, um motivo por parâmetro
+func parseExpenseFilter(r *http.Request) (*model.ExpenseFilter, filterErrors) {
+	query := r.URL.Query()
+	filter := &model.ExpenseFilter{}
+	errs := filterErrors{}
+
+	filter.StartDate = parseFilterDate(query, "start_date", errs)
+	filter.EndDate = parseFilterDate(query, "end_date", errs)
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		errs["end_date"] = "deve ser igual ou posterior a start_date"
+	}
+
+	filter.MinAmount = parseFilterAmount(query, "min_amount", errs)
+	filter.MaxAmount = parseFilterAmount(query, "max_amount", errs)
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount {
+		errs["max_amount"] = "deve ser maior ou igual a min_amount"
+	}
+
+	filter.CreatedAfter = parseFilterTime(query, "created_after", errs)
+	filter.UpdatedAfter = parseFilterTime(query, "updated_after", errs)
+
+	filter.Query = strings.TrimSpace(query.Get("q"))
+	if utf8.RuneCountInString(filter.Query) > maxFilterQueryLength {
+		errs["q"] = "deve ter no máximo " + strconv.Itoa(maxFilterQueryLength) + " caracteres"
+	}
+
+	for _, category := range query["category"] {
+		if category = strings.TrimSpace(category); category != "" {
+			filter.Categories = append(filter.Categories, model.Category(category))
+		}
+	}
+
+	filter.Tags = query["tag"]
+	switch query.Get("tag_match") {
+	case "", "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		errs["tag_match"] = "deve ser any ou all"
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return filter, nil
+}
+
+// applyPeriod restringe o filtro a um período predefinido (week, month ou
+// quarter). start_date e end_date continuam valendo, e vale a interseção dos
+// intervalos. Retorna false para períodos desconhecidos
+func applyPeriod(filter *model.ExpenseFilter, period string) bool {
+	dates := service.PeriodFilter(period)
+	if dates == nil {
+		return false
+	}
+
+	if filter.StartDate == nil || filter.StartDate.Before(*dates.StartDate) {
+		filter.StartDate = dates.StartDate
+	}
+	if filter.EndDate == nil || filter.EndDate.After(*dates.EndDate) {
+		filter.EndDate = dates.EndDate
+	}
+	return true
+}
+
+// parseFilterDate lê uma data no formato AAAA-MM-DD
+func parseFilterDate(query url.Values, name string, errs filterErrors) *time.Time {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		errs[name] = "data inválida, use o formato AAAA-MM-DD"
+		return nil
+	}
+	return &date
+}
+
+// parseFilterTime lê um instante em RFC 3339 ou uma data AAAA-MM-DD, que vale
+// como o início do dia em UTC
+func parseFilterTime(query url.Values, name string, errs filterErrors) *time.Time {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+	instant, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		instant, err = time.Parse("2006-01-02", value)
+	}
+	if err != nil {
+		errs[name] = "data inválida, use RFC 3339 (2024-03-01T10:00:00Z) ou AAAA-MM-DD"
+		return nil
+	}
+	return &instant
+}
+
+// parseFilterAmount lê um valor monetário como nas despesas ("10.50")
+func parseFilterAmount(query url.Values, name string, errs filterErrors) *model.Money {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+	amount, err := model.ParseMoney(value)
+	if err != nil {
+		errs[name] = err.Error()
+		return nil
+	}
+	if amount < 0 {
+		errs[name] = "não pode ser negativo"
+		return nil
+	}
+	return &amount
+}
+
+// invalidExpenseMessage descreve o erro de leitura do corpo da despesa. Erros no
+// valor (casas decimais a mais, formato ou limite) são informados ao cliente
+func invalidExpenseMessage(err error) string {
+	if errors.Is(err, model.ErrInvalidAmount) || errors.Is(err, model.ErrAmountScale) || errors.Is(err, model.ErrAmountRange) {
+		return err.Error()
+	}
+	return "dados inválidos"
+}
+
+// isExpenseInputError indica se o serviço recusou os dados da despesa
+func isExpenseInputError(err error) bool {
+	return errors.Is(err, service.ErrAmountNotPositive) ||
+		errors.Is(err, service.ErrUnsupportedCurrency) ||
+		errors.Is(err, currency.ErrInvalidCode) ||
+		errors.Is(err, service.ErrCategoryNotFound) ||
+		errors.Is(err, service.ErrCategoryArchived) ||
+		errors.Is(err, service.ErrInvalidTag) ||
+		errors.Is(err, service.ErrTooManyTags) ||
+		errors.Is(err, service.ErrMerchantTooLong) ||
+		errors.Is(err, service.ErrNotesTooLong)
 }
 
 // Update atualiza uma despesa existente
@@ -137,12 +368,16 @@ func (h *ExpenseHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	var input model.UpdateExpenseInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "dados inválidos", http.StatusBadRequest)
+		http.Error(w, invalidExpenseMessage(err), http.StatusBadRequest)
 		return
 	}
 
 	expense, err := h.service.Update(r.Context(), expenseID, userID, &input)
 	if err != nil {
+		if isExpenseInputError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"expenseapi/internal/auth"
+)
+
+// JWKSHandler publica as chaves públicas de validação dos tokens
+type JWKSHandler struct {
+	jwtService *auth.JWTService
+}
+
+// NewJWKSHandler cria uma nova instância do handler de JWKS
+func NewJWKSHandler(jwtService *auth.JWTService) *JWKSHandler {
+	return &JWKSHandler{jwtService: jwtService}
+}
+
+// Keys retorna o JWKS, permitindo que outros serviços validem os tokens sem o
+// segredo compartilhado
+func (h *JWKSHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, h.jwtService.JWKS())
+}
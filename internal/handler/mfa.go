@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// MFAHandler gerencia as requisições HTTP da autenticação em dois fatores
+type MFAHandler struct {
+	service *service.MFAService
+}
+
+// NewMFAHandler cria uma nova instância do handler de 2FA
+func NewMFAHandler(service *service.MFAService) *MFAHandler {
+	return &MFAHandler{service: service}
+}
+
+// Setup inicia a configuração do 2FA, retornando o segredo e a URI otpauth
+func (h *MFAHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.service.Setup(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Confirm ativa o 2FA com um código do autenticador
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.MFAConfirmInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.service.Confirm(r.Context(), userID, input)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Verify conclui o login de uma conta com 2FA
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var input model.MFAVerifyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if input.MFAToken == "" {
+		writeMessage(w, http.StatusBadRequest, "token de verificação não fornecido")
+		return
+	}
+
+	response, err := h.service.Verify(r.Context(), input, middleware.ClientInfo(r))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *MFAHandler) writeError(w http.ResponseWriter, err error) {
+	var rateLimit *service.RateLimitError
+	switch {
+	case errors.As(err, &rateLimit):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimit.RetryAfter.Seconds()))))
+		writeMessage(w, http.StatusTooManyRequests, "muitas tentativas de verificação, tente novamente mais tarde")
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		writeMessage(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrMFANotPending):
+		writeMessage(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidMFACode), errors.Is(err, service.ErrInvalidMFAToken):
+		writeMessage(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrUserNotFound):
+		writeMessage(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrAccountDisabled):
+		writeMessage(w, http.StatusForbidden, err.Error())
+	default:
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+	}
+}
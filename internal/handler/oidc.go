@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/oidc"
+	"expenseapi/internal/service"
+)
+
+// oidcStateCookie vincula o login em andamento ao navegador que o iniciou,
+// impedindo que um callback forjado conclua o login na sessão de outra pessoa
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler gerencia as requisições HTTP do login por OpenID Connect
+type OIDCHandler struct {
+	service *service.OIDCService
+}
+
+// NewOIDCHandler cria uma nova instância do handler OIDC
+func NewOIDCHandler(service *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{service: service}
+}
+
+// Start redireciona o usuário para a página de login do provedor
+func (h *OIDCHandler) Start(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := h.service.Start(r.Context(), r.PathValue("provider"))
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			writeMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("Erro ao iniciar login OIDC: %v", err)
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/v1/auth/oidc/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback recebe o retorno do provedor e conclui o login
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		writeMessage(w, http.StatusBadRequest, "login recusado pelo provedor: "+providerErr)
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		writeMessage(w, http.StatusBadRequest, service.ErrInvalidOIDCState.Error())
+		return
+	}
+
+	// O state é de uso único; o cookie é descartado em qualquer resultado
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/v1/auth/oidc/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response, err := h.service.Callback(r.Context(), r.PathValue("provider"), state, query.Get("code"), middleware.ClientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownProvider):
+			writeMessage(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidOIDCState):
+			writeMessage(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, oidc.ErrExchangeFailed), errors.Is(err, oidc.ErrInvalidIDToken):
+			log.Printf("Erro no callback OIDC: %v", err)
+			writeMessage(w, http.StatusUnauthorized, "falha na autenticação com o provedor")
+		case errors.Is(err, service.ErrOIDCEmailNotVerified), errors.Is(err, service.ErrEmailNotVerified),
+			errors.Is(err, service.ErrAccountDisabled):
+			writeMessage(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrOIDCAccountConflict):
+			writeMessage(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("Erro no callback OIDC: %v", err)
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
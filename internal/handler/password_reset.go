@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// PasswordResetHandler gerencia as requisições HTTP de redefinição de senha
+type PasswordResetHandler struct {
+	service *service.PasswordResetService
+}
+
+// NewPasswordResetHandler cria uma nova instância do handler de redefinição de senha
+func NewPasswordResetHandler(service *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{service: service}
+}
+
+// Forgot solicita o envio do email de redefinição de senha
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var input model.ForgotPasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if !emailRegex.MatchString(input.Email) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "email inválido",
+		})
+		return
+	}
+
+	if err := h.service.Forgot(r.Context(), input); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Erro interno do servidor",
+		})
+		return
+	}
+
+	// A resposta é a mesma exista ou não uma conta com o email informado
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "se o email estiver cadastrado, você receberá as instruções de redefinição",
+	})
+}
+
+// Reset redefine a senha a partir de um token recebido por email
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var input model.ResetPasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if input.Token == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "token não fornecido",
+		})
+		return
+	}
+
+	if err := h.service.Reset(r.Context(), input); err != nil {
+		if writePasswordPolicyError(w, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Erro interno do servidor",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// PersonalTokenHandler gerencia as requisições HTTP dos tokens de acesso pessoal
+type PersonalTokenHandler struct {
+	service *service.PersonalTokenService
+}
+
+// NewPersonalTokenHandler cria uma nova instância do handler de tokens pessoais
+func NewPersonalTokenHandler(service *service.PersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{service: service}
+}
+
+// Create cria um novo token pessoal para o usuário autenticado
+func (h *PersonalTokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.CreatePersonalTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	claims := middleware.GetClaimsFromContext(r.Context())
+	response, err := h.service.Create(r.Context(), userID, input, claims != nil && claims.ReadOnly)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWriteScopeNotAllowed):
+			writeMessage(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrInvalidTokenName),
+			errors.Is(err, service.ErrInvalidTokenScope),
+			errors.Is(err, service.ErrInvalidTokenExpiry):
+			writeMessage(w, http.StatusBadRequest, err.Error())
+		default:
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// List lista os tokens pessoais do usuário autenticado
+func (h *PersonalTokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// Delete revoga um token pessoal do usuário autenticado
+func (h *PersonalTokenHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), userID, r.PathValue("id")); err != nil {
+		if errors.Is(err, service.ErrPersonalTokenNotFound) {
+			writeMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// PrivacyHandler gerencia a exportação dos dados e a exclusão da conta
+type PrivacyHandler struct {
+	service *service.PrivacyService
+}
+
+// NewPrivacyHandler cria uma nova instância do handler de privacidade
+func NewPrivacyHandler(service *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: service}
+}
+
+// Export envia um arquivo zip com todos os dados do usuário autenticado
+func (h *PrivacyHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	// O arquivo é montado em memória para que um erro ainda possa ser respondido
+	var archive bytes.Buffer
+	if err := h.service.WriteArchive(r.Context(), userID, &archive); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			writeMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	filename := fmt.Sprintf("expenseapi-export-%s.zip", time.Now().UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	archive.WriteTo(w)
+}
+
+// Delete agenda a exclusão da conta do usuário autenticado
+func (h *PrivacyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	var input model.DeleteAccountInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if input.Password == "" {
+		writeMessage(w, http.StatusBadRequest, "senha não fornecida")
+		return
+	}
+
+	response, err := h.service.RequestDeletion(r.Context(), userID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			writeMessage(w, http.StatusUnauthorized, "senha incorreta")
+		case errors.Is(err, service.ErrUserNotFound):
+			writeMessage(w, http.StatusNotFound, err.Error())
+		default:
+			writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, response)
+}
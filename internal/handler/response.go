@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/service"
+)
+
+// writeJSON escreve v como JSON com o código de status informado
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeMessage escreve uma resposta no formato {"message": "..."}
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"message": message,
+	})
+}
+
+// writePasswordPolicyError responde 400 com as regras da política de senhas não
+// atendidas. Retorna false se err não for uma violação da política
+func writePasswordPolicyError(w http.ResponseWriter, err error) bool {
+	var policyErr *service.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"message":    policyErr.Error(),
+		"violations": policyErr.Violations,
+	})
+	return true
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/service"
+)
+
+// SessionHandler gerencia as requisições HTTP das sessões do usuário
+type SessionHandler struct {
+	authService *service.AuthService
+}
+
+// NewSessionHandler cria uma nova instância do handler de sessões
+func NewSessionHandler(authService *service.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+// List lista as sessões ativas do usuário autenticado
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaimsFromContext(r.Context())
+	if claims == nil {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(r.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+// Delete encerra uma sessão do usuário autenticado, inclusive a atual
+func (h *SessionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userID, r.PathValue("id")); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			writeMessage(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/service"
+)
+
+// TagHandler gerencia as requisições HTTP das tags de despesas
+type TagHandler struct {
+	service *service.TagService
+}
+
+// NewTagHandler cria uma nova instância do handler de tags
+func NewTagHandler(service *service.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// List lista as tags em uso pelo usuário autenticado, com a quantidade de
+// despesas de cada uma
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "usuário não autenticado", http.StatusUnauthorized)
+		return
+	}
+
+	tags, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
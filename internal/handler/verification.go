@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/service"
+)
+
+// VerificationHandler gerencia as requisições HTTP de verificação de email
+type VerificationHandler struct {
+	service *service.VerificationService
+}
+
+// NewVerificationHandler cria uma nova instância do handler de verificação de email
+func NewVerificationHandler(service *service.VerificationService) *VerificationHandler {
+	return &VerificationHandler{service: service}
+}
+
+// Verify confirma o email com o token recebido
+func (h *VerificationHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var input model.ConfirmEmailInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	if input.Token == "" {
+		writeMessage(w, http.StatusBadRequest, "token não fornecido")
+		return
+	}
+
+	user, err := h.service.Verify(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidEmailToken) || errors.Is(err, service.ErrUserNotFound) {
+			writeMessage(w, http.StatusBadRequest, service.ErrInvalidEmailToken.Error())
+			return
+		}
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// Resend reenvia o email de verificação
+func (h *VerificationHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	var input model.ResendVerificationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Erro ao decodificar requisição", http.StatusBadRequest)
+		return
+	}
+
+	// Validação do email
+	if !emailRegex.MatchString(input.Email) {
+		writeMessage(w, http.StatusBadRequest, "email inválido")
+		return
+	}
+
+	if err := h.service.Resend(r.Context(), input); err != nil {
+		writeMessage(w, http.StatusInternalServerError, "Erro interno do servidor")
+		return
+	}
+
+	// A resposta é a mesma exista ou não uma conta pendente com o email informado
+	writeMessage(w, http.StatusAccepted, "se o email estiver pendente de verificação, você receberá um novo link")
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"context"
+
+	"expenseapi/internal/config"
+)
+
+// Message representa um email a ser enviado
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer é a interface que define o envio de emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New cria o mailer configurado: "smtp" envia de fato os emails, qualquer
+// outro valor registra as mensagens no log (e em arquivos, se configurado)
+func New(cfg config.MailConfig) Mailer {
+	if cfg.Driver == "smtp" {
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.From)
+	}
+	return NewLogMailer(cfg.OutputDir)
+}
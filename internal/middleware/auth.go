@@ -6,14 +6,24 @@ import (
 	"strings"
 
 	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "user_id"
+const (
+	UserIDKey contextKey = "user_id"
+	ClaimsKey contextKey = "claims"
+	RoleKey   contextKey = "role"
+)
+
+// Authenticator valida um token de acesso e retorna as suas claims
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.Claims, error)
+}
 
 // AuthMiddleware protege rotas que requerem autenticação
-func AuthMiddleware(jwtService *auth.JWTService, next http.HandlerFunc) http.HandlerFunc {
+func AuthMiddleware(authenticator Authenticator, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -28,17 +38,67 @@ func AuthMiddleware(jwtService *auth.JWTService, next http.HandlerFunc) http.Han
 		}
 
 		token := parts[1]
-		claims, err := jwtService.ValidateToken(token)
+		claims, err := authenticator.Authenticate(r.Context(), token)
 		if err != nil {
 			http.Error(w, "token inválido", http.StatusUnauthorized)
 			return
 		}
 
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, ClaimsKey, claims)
+		ctx = context.WithValue(ctx, RoleKey, claims.Role)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
 
+// RequireWriteAccess bloqueia tokens restritos à leitura. Deve ser usado
+// dentro do AuthMiddleware nas rotas que alteram dados
+func RequireWriteAccess(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if claims := GetClaimsFromContext(r.Context()); claims != nil && claims.ReadOnly {
+			http.Error(w, "acesso somente leitura", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// RequireScope exige que o token tenha o escopo informado. Tokens de sessão têm
+// acesso completo; tokens pessoais só passam com o escopo concedido
+func RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if claims := GetClaimsFromContext(r.Context()); claims == nil || !claims.HasScope(scope) {
+			http.Error(w, "escopo insuficiente", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// RequireSession bloqueia tokens pessoais, restringindo a rota a sessões
+// iniciadas com login. Usado no gerenciamento da conta
+func RequireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if claims := GetClaimsFromContext(r.Context()); claims == nil || claims.Type != auth.TokenTypeAccess {
+			http.Error(w, "rota exige uma sessão de login", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// RequireRole restringe a rota aos usuários com o papel informado. Deve ser
+// usado dentro do AuthMiddleware
+func RequireRole(role model.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if GetRoleFromContext(r.Context()) != role {
+			http.Error(w, "acesso negado", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
 // GetUserIDFromContext retorna o ID do usuário do contexto
 func GetUserIDFromContext(ctx context.Context) string {
 	if userID, ok := ctx.Value(UserIDKey).(string); ok {
@@ -46,3 +106,20 @@ func GetUserIDFromContext(ctx context.Context) string {
 	}
 	return ""
 }
+
+// GetRoleFromContext retorna o papel do usuário autenticado. Tokens emitidos
+// antes da introdução dos papéis são tratados como usuários comuns
+func GetRoleFromContext(ctx context.Context) model.Role {
+	if role, ok := ctx.Value(RoleKey).(model.Role); ok && role != "" {
+		return role
+	}
+	return model.RoleUser
+}
+
+// GetClaimsFromContext retorna as claims do token autenticado
+func GetClaimsFromContext(ctx context.Context) *auth.Claims {
+	if claims, ok := ctx.Value(ClaimsKey).(*auth.Claims); ok {
+		return claims
+	}
+	return nil
+}
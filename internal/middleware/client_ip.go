@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"expenseapi/internal/model"
+)
+
+// ParseTrustedProxies interpreta a lista de proxies confiáveis, com endereços
+// IP ou redes no formato CIDR
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("proxy confiável inválido: %q", value)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("proxy confiável inválido: %q", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// RealIP substitui o RemoteAddr pelo IP do cliente informado no header
+// X-Forwarded-For. Cada proxy acrescenta ao final do header o endereço de quem
+// o chamou, e o cliente pode forjar o início; por isso o header é lido da
+// direita para a esquerda e vale o primeiro endereço que não é de um proxy
+// confiável. Sem proxies informados, apenas o proxy imediato é confiável e vale
+// o último endereço. Requisições que não vêm de um proxy confiável são mantidas
+func RealIP(trusted []*net.IPNet, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded := r.Header.Values("X-Forwarded-For")
+		if len(forwarded) == 0 || (len(trusted) > 0 && !isTrustedProxy(trusted, ClientIP(r))) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entries := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(entries) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(entries[i]))
+			if ip == nil {
+				break
+			}
+			// Mesmo que todos sejam proxies, o mais distante é o melhor palpite
+			r.RemoteAddr = ip.String()
+			if !isTrustedProxy(trusted, r.RemoteAddr) {
+				break
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isTrustedProxy indica se o endereço pertence a um dos proxies confiáveis
+func isTrustedProxy(trusted []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP retorna o endereço IP de origem da requisição, sem a porta
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientInfo retorna o IP e o user agent da requisição, registrados nas sessões
+func ClientInfo(r *http.Request) model.ClientInfo {
+	return model.ClientInfo{IP: ClientIP(r), UserAgent: r.UserAgent()}
+}
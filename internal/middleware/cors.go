@@ -27,7 +27,7 @@ func CORS(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-CSRF-Token")
 
 		// Expor headers
-		w.Header().Set("Access-Control-Expose-Headers", "Authorization")
+		w.Header().Set("Access-Control-Expose-Headers", "Authorization, Retry-After, Content-Disposition")
 
 		// Permitir credenciais
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
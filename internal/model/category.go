@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// UserCategory é uma categoria de despesas definida pelo usuário. Categorias
+// arquivadas continuam nas despesas existentes, mas não podem ser usadas em
+// novas despesas. Subcategorias apontam para a categoria pai em ParentID
+type UserCategory struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	ParentID  *string   `json:"parent_id"`
+	Name      Category  `json:"name"`
+	Color     *string   `json:"color"`
+	Icon      *string   `json:"icon"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateCategoryInput representa os dados para criar uma categoria
+type CreateCategoryInput struct {
+	Name     string  `json:"name" validate:"required,max=50"`
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	Color    *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Icon     *string `json:"icon,omitempty" validate:"omitempty,max=50"`
+}
+
+// UpdateCategoryInput representa os dados que podem ser alterados em uma
+// categoria. ParentID vazio move a categoria para o nível principal
+type UpdateCategoryInput struct {
+	Name     *string `json:"name,omitempty" validate:"omitempty,max=50"`
+	ParentID *string `json:"parent_id,omitempty"`
+	Color    *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Icon     *string `json:"icon,omitempty" validate:"omitempty,max=50"`
+	Archived *bool   `json:"archived,omitempty"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ExchangeRate é a cotação de uma moeda em uma data, em unidades da moeda por
+// 1 EUR (como nas tabelas do Banco Central Europeu)
+type ExchangeRate struct {
+	Date     time.Time `json:"date"`
+	Currency string    `json:"currency"`
+	// Rate é o valor decimal exato da cotação, como "5.4321"
+	Rate string `json:"rate"`
+}
+
+// ExchangeRateInput representa uma cotação enviada à API administrativa
+type ExchangeRateInput struct {
+	Date     string      `json:"date" validate:"required,datetime=2006-01-02"`
+	Currency string      `json:"currency" validate:"required,len=3"`
+	Rate     json.Number `json:"rate" validate:"required"`
+}
+
+// ImportRatesResponse informa quantas cotações foram importadas
+type ImportRatesResponse struct {
+	Imported int `json:"imported"`
+}
+
+// UpdateBaseCurrencyInput representa a troca da moeda base do usuário
+type UpdateBaseCurrencyInput struct {
+	BaseCurrency string `json:"base_currency" validate:"required,len=3"`
+}
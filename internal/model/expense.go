@@ -4,7 +4,8 @@ import (
 	"time"
 )
 
-// Category representa as categorias possíveis de despesas
+// Category é o nome de uma categoria de despesas. Cada usuário tem as próprias
+// categorias; as constantes abaixo são as criadas por padrão para novos usuários
 type Category string
 
 const (
@@ -19,35 +20,186 @@ const (
 
 // Expense representa uma despesa no sistema
 type Expense struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	Amount      float64   `json:"amount"`
-	Description string    `json:"description"`
-	Category    Category  `json:"category"`
-	Date        time.Time `json:"date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Amount Money  `json:"amount"`
+	// Currency é o código ISO 4217 da moeda em que a despesa foi paga
+	Currency string `json:"currency"`
+	// BaseAmount é o valor convertido para a moeda base do usuário com a cotação
+	// da data da despesa. Fica vazio quando não há cotação para a data
+	BaseAmount   *Money `json:"base_amount,omitempty"`
+	BaseCurrency string `json:"base_currency,omitempty"`
+	Description  string `json:"description"`
+	// Merchant é o estabelecimento onde a despesa foi feita
+	Merchant   string   `json:"merchant"`
+	Notes      string   `json:"notes"`
+	Category   Category `json:"category"`
+	CategoryID string   `json:"category_id"`
+	// Tags são os nomes das tags da despesa, em minúsculas e ordem alfabética
+	Tags      []string  `json:"tags"`
+	Date      time.Time `json:"date"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // CreateExpenseInput representa os dados necessários para criar uma nova despesa
 type CreateExpenseInput struct {
-	Amount      float64  `json:"amount" validate:"required,gt=0"`
+	Amount Money `json:"amount" validate:"required,gt=0"`
+	// Currency é opcional; por padrão, a moeda base do usuário
+	Currency    string   `json:"currency,omitempty" validate:"omitempty,len=3"`
 	Description string   `json:"description" validate:"required,min=3,max=255"`
-	Category    Category `json:"category" validate:"required,oneof=MANTIMENTOS LAZER ELETRONICA UTILITARIOS ROUPAS SAUDE OUTROS"`
+	Merchant    string   `json:"merchant,omitempty" validate:"omitempty,max=255"`
+	Notes       string   `json:"notes,omitempty" validate:"omitempty,max=2000"`
+	Category    Category `json:"category" validate:"required,max=50"`
+	Tags        []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
 	Date        string   `json:"date" validate:"required,datetime=2006-01-02"`
 }
 
 // UpdateExpenseInput representa os dados que podem ser atualizados em uma despesa
 type UpdateExpenseInput struct {
-	Amount      *float64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Amount      *Money    `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Currency    *string   `json:"currency,omitempty" validate:"omitempty,len=3"`
 	Description *string   `json:"description,omitempty" validate:"omitempty,min=3,max=255"`
-	Category    *Category `json:"category,omitempty" validate:"omitempty,oneof=MANTIMENTOS LAZER ELETRONICA UTILITARIOS ROUPAS SAUDE OUTROS"`
-	Date        *string   `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Merchant    *string   `json:"merchant,omitempty" validate:"omitempty,max=255"`
+	Notes       *string   `json:"notes,omitempty" validate:"omitempty,max=2000"`
+	Category    *Category `json:"category,omitempty" validate:"omitempty,max=50"`
+	// Tags substitui todas as tags da despesa; uma lista vazia remove as tags
+	Tags *[]string `json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Date *string   `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
 }
 
 // ExpenseFilter representa os filtros disponíveis para busca de despesas
 type ExpenseFilter struct {
 	StartDate *time.Time `json:"start_date,omitempty"`
 	EndDate   *time.Time `json:"end_date,omitempty"`
-	Category  *Category  `json:"category,omitempty"`
+	// Categories filtra as despesas de qualquer uma das categorias, incluindo
+	// as subcategorias
+	Categories []Category `json:"categories,omitempty"`
+	// MinAmount e MaxAmount limitam o valor na moeda original, inclusive
+	MinAmount *Money `json:"min_amount,omitempty"`
+	MaxAmount *Money `json:"max_amount,omitempty"`
+	// Query busca o texto na descrição, sem diferenciar maiúsculas
+	Query string `json:"q,omitempty"`
+	// CreatedAfter e UpdatedAfter trazem as despesas criadas ou alteradas
+	// depois do instante informado
+	CreatedAfter *time.Time `json:"created_after,omitempty"`
+	UpdatedAfter *time.Time `json:"updated_after,omitempty"`
+	// Tags filtra as despesas com ao menos uma das tags ou, com MatchAllTags,
+	// com todas elas
+	Tags         []string `json:"tags,omitempty"`
+	MatchAllTags bool     `json:"match_all_tags,omitempty"`
+}
+
+// Campos pelos quais a listagem de despesas pode ser ordenada
+const (
+	ExpenseSortDate        = "date"
+	ExpenseSortAmount      = "amount"
+	ExpenseSortCreatedAt   = "created_at"
+	ExpenseSortDescription = "description"
+)
+
+// ExpenseListParams são os parâmetros de paginação e ordenação da listagem
+// recebidos do cliente
+type ExpenseListParams struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	Order  string
+}
+
+// ExpenseCursor marca a última despesa de uma página: o valor do campo de
+// ordenação e o ID, que desempata despesas com o mesmo valor. Sort e Desc
+// garantem que o cursor só seja usado com a mesma ordenação
+type ExpenseCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// ExpensePageQuery é a página de despesas pedida ao repositório
+type ExpensePageQuery struct {
+	Sort  string
+	Desc  bool
+	Limit int
+	// After é a última despesa da página anterior; vazio na primeira página
+	After *ExpenseCursor
+}
+
+// ExpensePage é uma página da listagem de despesas. NextCursor fica vazio na
+// última página
+type ExpensePage struct {
+	Items      []*Expense `json:"items"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
+// ExpenseSearchResult é uma despesa encontrada pela busca textual, com a
+// relevância e os trechos em que os termos foram encontrados
+type ExpenseSearchResult struct {
+	Expense
+	Rank       float64           `json:"rank"`
+	Highlights ExpenseHighlights `json:"highlights"`
+}
+
+// ExpenseHighlights traz os trechos de cada campo com os termos buscados entre
+// <mark> e </mark>. O restante do texto vem escapado como HTML; campos sem
+// nenhum termo encontrado ficam vazios
+type ExpenseHighlights struct {
+	Description string `json:"description,omitempty"`
+	Merchant    string `json:"merchant,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// ExpenseSearchResults é a resposta da busca textual, da despesa mais relevante
+// para a menos relevante
+type ExpenseSearchResults struct {
+	Items []*ExpenseSearchResult `json:"items"`
+}
+
+// TagUsage é uma tag do usuário com a quantidade de despesas que a usam
+type TagUsage struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ExpenseGroupTotal é a soma das despesas de uma categoria, moeda e data. É a
+// menor unidade de agregação que pode ser convertida com uma única cotação
+type ExpenseGroupTotal struct {
+	CategoryID string
+	Category   Category
+	Currency   string
+	Date       time.Time
+	Total      Money
+	Count      int
+}
+
+// CategoryTotal é o total gasto em uma categoria, na moeda base. O total e a
+// contagem incluem as despesas de todas as subcategorias
+type CategoryTotal struct {
+	CategoryID string   `json:"category_id"`
+	ParentID   *string  `json:"parent_id"`
+	Category   Category `json:"category"`
+	Total      Money    `json:"total"`
+	Count      int      `json:"count"`
+}
+
+// CurrencyTotal é o total gasto em uma moeda, no valor original e convertido
+// para a moeda base. BaseAmount fica vazio se faltar cotação para alguma data
+type CurrencyTotal struct {
+	Currency   string `json:"currency"`
+	Amount     Money  `json:"amount"`
+	BaseAmount *Money `json:"base_amount"`
+	Count      int    `json:"count"`
+}
+
+// ExpenseSummary resume as despesas de um período na moeda base do usuário,
+// somadas de forma exata. Incomplete indica que faltou cotação para alguma
+// despesa, que então não entra nos totais convertidos
+type ExpenseSummary struct {
+	BaseCurrency string          `json:"base_currency"`
+	Total        Money           `json:"total"`
+	Count        int             `json:"count"`
+	Incomplete   bool            `json:"incomplete"`
+	Currencies   []CurrencyTotal `json:"currencies"`
+	Categories   []CategoryTotal `json:"categories"`
 }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginAttempt representa o contador de falhas de login de uma chave (conta ou IP)
+type LoginAttempt struct {
+	Key         string     `json:"key"`
+	Failures    int        `json:"failures"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// LoginEventResult identifica o resultado de uma tentativa de login
+type LoginEventResult string
+
+const (
+	LoginEventSuccess LoginEventResult = "success"
+	LoginEventFailure LoginEventResult = "failure"
+	LoginEventBlocked LoginEventResult = "blocked"
+)
+
+// LoginEvent registra uma tentativa de login para auditoria
+type LoginEvent struct {
+	Email     string           `json:"email"`
+	IP        string           `json:"ip"`
+	UserAgent string           `json:"user_agent"`
+	Result    LoginEventResult `json:"result"`
+	CreatedAt time.Time        `json:"created_at"`
+}
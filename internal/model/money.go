@@ -0,0 +1,193 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount = errors.New("valor inválido")
+	ErrAmountScale   = errors.New("valor deve ter no máximo 2 casas decimais")
+	ErrAmountRange   = errors.New("valor excede o máximo permitido")
+)
+
+// MoneyScale é o número de casas decimais dos valores monetários
+const MoneyScale = 2
+
+// Money é um valor monetário exato, armazenado em centavos. No JSON é escrito
+// como número com duas casas decimais (150.50) e aceito como número ou string
+type Money int64
+
+// MoneyFromCents cria um valor a partir da quantidade de centavos
+func MoneyFromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// ParseMoney converte um valor decimal ("150.5", "-0.30") sem passar por
+// ponto flutuante. Valores com mais de duas casas decimais são recusados
+func ParseMoney(s string) (Money, error) {
+	return parseMoney(s, maxWholeDigits)
+}
+
+const (
+	// maxWholeDigits é a parte inteira da coluna DECIMAL(10,2)
+	maxWholeDigits = 8
+	// maxTotalDigits limita somas lidas do banco ao que cabe em int64
+	maxTotalDigits = 16
+)
+
+func parseMoney(s string, wholeDigits int) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+	if len(fraction) > MoneyScale {
+		return 0, ErrAmountScale
+	}
+
+	// Os dígitos são limitados antes da conversão para evitar estouro
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole) > wholeDigits {
+		return 0, ErrAmountRange
+	}
+	fraction += strings.Repeat("0", MoneyScale-len(fraction))
+
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+// MustParseMoney é como ParseMoney, mas entra em pânico se o valor for inválido.
+// Deve ser usado apenas com constantes
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(fmt.Sprintf("valor monetário inválido %q: %v", s, err))
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cents retorna o valor em centavos
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// IsPositive indica se o valor é maior que zero
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+// Add soma dois valores
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// SumMoney soma os valores de forma exata
+func SumMoney(values ...Money) Money {
+	var total Money
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// String formata o valor com duas casas decimais, como "150.50"
+func (m Money) String() string {
+	cents := int64(m)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		if cents == math.MinInt64 {
+			return "-92233720368547758.08"
+		}
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	// Aceita o valor como string ("150.50") ou como número (150.5); o texto
+	// do número é interpretado diretamente, sem conversão para float64
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return ErrInvalidAmount
+		}
+		data = []byte(s)
+	}
+
+	value, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
+
+// Scan lê o valor de uma coluna DECIMAL
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return m.scanText(v)
+	case []byte:
+		return m.scanText(string(v))
+	case int64:
+		*m = Money(v * 100)
+		return nil
+	case nil:
+		*m = 0
+		return nil
+	default:
+		return fmt.Errorf("tipo incompatível com Money: %T", src)
+	}
+}
+
+// scanText lê a representação textual do DECIMAL. Resultados de SUM podem ter
+// zeros à direita além da escala e passar do limite da coluna
+func (m *Money) scanText(s string) error {
+	if whole, fraction, ok := strings.Cut(s, "."); ok && len(fraction) > MoneyScale {
+		if strings.Trim(fraction[MoneyScale:], "0") != "" {
+			return ErrAmountScale
+		}
+		s = whole + "." + fraction[:MoneyScale]
+	}
+
+	value, err := parseMoney(s, maxTotalDigits)
+	if err != nil {
+		return err
+	}
+	*m = value
+	return nil
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// OIDCState guarda os dados de um login OIDC em andamento, entre o
+// redirecionamento ao provedor e o callback
+type OIDCState struct {
+	StateHash    string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// UserIdentity vincula um usuário a uma conta em um provedor externo
+type UserIdentity struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
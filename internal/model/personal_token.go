@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// PersonalToken é um token de acesso pessoal usado por scripts e integrações
+type PersonalToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Hint       string     `json:"hint"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsExpired indica se o token já expirou
+func (t *PersonalToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
+
+// CreatePersonalTokenInput representa os dados para criação de um token pessoal
+type CreatePersonalTokenInput struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatePersonalTokenResponse traz o token em texto puro, exibido uma única vez
+type CreatePersonalTokenResponse struct {
+	PersonalToken
+	Token string `json:"token"`
+}
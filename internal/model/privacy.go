@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// DeleteAccountInput confirma o pedido de exclusão da conta com a senha atual
+type DeleteAccountInput struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// DeleteAccountResponse informa quando a conta será excluída definitivamente
+type DeleteAccountResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// AccountExport reúne os dados pessoais do usuário para portabilidade
+type AccountExport struct {
+	ExportedAt time.Time  `json:"exported_at"`
+	User       *User      `json:"user"`
+	Expenses   []*Expense `json:"expenses"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// ClientInfo identifica o dispositivo que originou uma requisição
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Session representa um login ativo. O ID é o mesmo da família de refresh
+// tokens emitida no login, que é renovada enquanto a sessão estiver em uso
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+	// Current indica a sessão do token usado na requisição
+	Current bool `json:"current"`
+}
+
+// RevokedSession invalida os tokens de acesso de uma sessão encerrada
+type RevokedSession struct {
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken representa um refresh token emitido e persistido (apenas o hash)
+type RefreshToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenInput representa os dados necessários para renovar os tokens
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RevokedToken representa um token de acesso revogado individualmente (pelo jti)
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UserRevocation revoga todos os tokens de um usuário emitidos antes de RevokedBefore
+type UserRevocation struct {
+	UserID        string    `json:"user_id"`
+	RevokedBefore time.Time `json:"revoked_before"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// LogoutInput representa os dados opcionais enviados no logout
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// PasswordResetToken representa um token de redefinição de senha (apenas o hash é armazenado)
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// EmailTokenPurpose identifica a finalidade de um token enviado por email
+type EmailTokenPurpose string
+
+const (
+	EmailTokenChangeEmail EmailTokenPurpose = "change_email"
+	EmailTokenVerifyEmail EmailTokenPurpose = "verify_email"
+)
+
+// EmailToken representa um token de confirmação enviado para um endereço de email
+type EmailToken struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Email     string            `json:"email"`
+	Purpose   EmailTokenPurpose `json:"purpose"`
+	TokenHash string            `json:"-"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	UsedAt    *time.Time        `json:"used_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
@@ -3,13 +3,40 @@ package model
 import (
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"expenseapi/internal/password"
 )
 
+// Role define o nível de acesso do usuário
+type Role string
+
+const (
+	RoleUser     Role = "user"
+	RoleAdmin    Role = "admin"
+	RoleReadOnly Role = "read_only"
+)
+
+// IsValid indica se o papel é um dos papéis conhecidos
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleAdmin, RoleReadOnly:
+		return true
+	}
+	return false
+}
+
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	Role            Role       `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPSecret      *string    `json:"-"`
+	MFAEnabledAt    *time.Time `json:"mfa_enabled_at"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	// DeletionScheduledAt é a data em que a conta será excluída definitivamente
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	// BaseCurrency é a moeda (ISO 4217) em que os totais do usuário são exibidos
+	BaseCurrency string    `json:"base_currency"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -22,21 +49,115 @@ type CreateUserInput struct {
 type LoginInput struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// IP e UserAgent são preenchidos a partir da requisição, não do corpo
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 type LoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// MFARequired indica que a senha foi aceita, mas o login só é concluído
+	// trocando o MFAToken e um código do autenticador em /auth/2fa/verify
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
 
-func (u *User) ComparePassword(password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
+// IsEmailVerified indica se o usuário já confirmou o email
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
-func HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedBytes), nil
+// IsDisabled indica se a conta foi desativada por um administrador
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// IsMFAEnabled indica se o usuário ativou a autenticação em dois fatores
+func (u *User) IsMFAEnabled() bool {
+	return u.MFAEnabledAt != nil && u.TOTPSecret != nil
+}
+
+// ComparePassword confere a senha com o hash armazenado, em qualquer dos
+// formatos aceitos (Argon2id ou bcrypt legado)
+func (u *User) ComparePassword(plain string) error {
+	return password.Verify(u.PasswordHash, plain)
+}
+
+// PasswordNeedsRehash indica se o hash da senha é mais fraco que a política atual
+func (u *User) PasswordNeedsRehash() bool {
+	return password.NeedsRehash(u.PasswordHash)
+}
+
+// HashPassword gera o hash da senha com o algoritmo atual
+func HashPassword(plain string) (string, error) {
+	return password.Hash(plain)
+}
+
+// ForgotPasswordInput representa os dados para solicitar a redefinição de senha
+type ForgotPasswordInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordInput representa os dados para redefinir a senha com um token
+type ResetPasswordInput struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// ChangePasswordInput representa os dados para trocar a senha do usuário autenticado
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// ChangeEmailInput representa os dados para trocar o email do usuário autenticado
+type ChangeEmailInput struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ConfirmEmailInput representa o token recebido por email para confirmação
+type ConfirmEmailInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationInput representa os dados para reenviar o email de verificação
+type ResendVerificationInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// MFASetupResponse contém o segredo TOTP a ser cadastrado no autenticador
+type MFASetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAConfirmInput representa o código usado para confirmar a ativação do 2FA
+type MFAConfirmInput struct {
+	Code string `json:"code" validate:"required,len=6"`
+}
+
+// MFAConfirmResponse contém os códigos de recuperação, exibidos uma única vez
+type MFAConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAVerifyInput representa a segunda etapa do login com 2FA. Deve ser
+// informado o código do autenticador ou um código de recuperação
+type MFAVerifyInput struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// UpdateRoleInput representa a troca de papel de um usuário por um administrador
+type UpdateRoleInput struct {
+	Role Role `json:"role" validate:"required"`
+}
+
+// PasswordViolation descreve uma regra da política de senhas não atendida
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,81 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// rangePrefixLength é o tamanho do prefixo do SHA-1 usado para agrupar os
+// hashes, o mesmo do modelo de k-anonimato do Have I Been Pwned
+const rangePrefixLength = 5
+
+// BreachedList é uma lista local de hashes SHA-1 de senhas vazadas, agrupada
+// pelo prefixo do hash. A consulta segue o modelo de k-anonimato: apenas o
+// prefixo seleciona o grupo e o sufixo é procurado dentro dele
+type BreachedList struct {
+	ranges map[string][]string
+}
+
+// LoadBreachedList carrega a lista do arquivo informado. Cada linha contém um
+// hash SHA-1 em hexadecimal, opcionalmente seguido de ":contagem", como nos
+// arquivos do Have I Been Pwned. Linhas vazias e iniciadas por # são ignoradas
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachedList{ranges: make(map[string][]string)}
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(text, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("linha %d: hash SHA-1 inválido", line)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("linha %d: hash SHA-1 inválido", line)
+		}
+
+		prefix := hash[:rangePrefixLength]
+		list.ranges[prefix] = append(list.ranges[prefix], hash[rangePrefixLength:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, suffixes := range list.ranges {
+		sort.Strings(suffixes)
+	}
+	return list, nil
+}
+
+// Range retorna os sufixos dos hashes que começam com o prefixo informado
+func (l *BreachedList) Range(prefix string) []string {
+	return l.ranges[strings.ToUpper(prefix)]
+}
+
+// Contains indica se a senha aparece na lista
+func (l *BreachedList) Contains(plain string) bool {
+	sum := sha1.Sum([]byte(plain))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes := l.Range(hash[:rangePrefixLength])
+	suffix := hash[rangePrefixLength:]
+	i := sort.SearchStrings(suffixes, suffix)
+	return i < len(suffixes) && suffixes[i] == suffix
+}
@@ -0,0 +1,93 @@
+// Package password implementa o hash de senhas com algoritmos versionados.
+// Os hashes são gravados com um prefixo que identifica o algoritmo, o que
+// permite trocar o algoritmo atual sem invalidar as senhas já cadastradas
+package password
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var (
+	ErrMismatch      = errors.New("senha incorreta")
+	ErrUnknownFormat = errors.New("formato de hash de senha desconhecido")
+)
+
+// Hasher é um algoritmo de hash de senha
+type Hasher interface {
+	// Matches indica se o hash foi gerado por este algoritmo
+	Matches(hash string) bool
+	// Hash gera o hash da senha com os parâmetros atuais
+	Hash(password string) (string, error)
+	// Verify confere a senha, retornando ErrMismatch se ela não corresponder
+	Verify(hash, password string) error
+	// NeedsRehash indica se o hash foi gerado com parâmetros mais fracos que os atuais
+	NeedsRehash(hash string) bool
+}
+
+// Manager gera hashes com o algoritmo atual e confere hashes de qualquer
+// algoritmo conhecido
+type Manager struct {
+	current Hasher
+	hashers []Hasher
+}
+
+// NewManager cria um gerenciador que usa current para novos hashes e ainda
+// aceita os hashes dos algoritmos legados
+func NewManager(current Hasher, legacy ...Hasher) *Manager {
+	return &Manager{
+		current: current,
+		hashers: append([]Hasher{current}, legacy...),
+	}
+}
+
+// Hash gera o hash da senha com o algoritmo atual
+func (m *Manager) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+// Verify confere a senha com o algoritmo que gerou o hash
+func (m *Manager) Verify(hash, password string) error {
+	for _, hasher := range m.hashers {
+		if hasher.Matches(hash) {
+			return hasher.Verify(hash, password)
+		}
+	}
+	return ErrUnknownFormat
+}
+
+// NeedsRehash indica se o hash deve ser regerado: foi gerado por outro
+// algoritmo ou com parâmetros mais fracos que os atuais
+func (m *Manager) NeedsRehash(hash string) bool {
+	if !m.current.Matches(hash) {
+		return true
+	}
+	return m.current.NeedsRehash(hash)
+}
+
+var defaultManager atomic.Pointer[Manager]
+
+func init() {
+	defaultManager.Store(NewManager(NewArgon2idHasher(DefaultArgon2idParams), NewBcryptHasher(0)))
+}
+
+// SetDefault substitui o gerenciador usado pelas funções do pacote. Deve ser
+// chamado na inicialização, antes de atender requisições
+func SetDefault(m *Manager) {
+	defaultManager.Store(m)
+}
+
+// Hash gera o hash da senha com o gerenciador padrão
+func Hash(password string) (string, error) {
+	return defaultManager.Load().Hash(password)
+}
+
+// Verify confere a senha com o gerenciador padrão
+func Verify(hash, password string) error {
+	return defaultManager.Load().Verify(hash, password)
+}
+
+// NeedsRehash indica se o hash deve ser regerado segundo o gerenciador padrão
+func NeedsRehash(hash string) bool {
+	return defaultManager.Load().NeedsRehash(hash)
+}
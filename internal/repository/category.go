@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInUse indica que o registro não pode ser removido por ainda ser referenciado
+var ErrInUse = errors.New("registro em uso")
+
+// CategoryRepository gerencia as categorias de despesas dos usuários
+type CategoryRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewCategoryRepository cria uma nova instância do repositório de categorias
+func NewCategoryRepository(db *pgxpool.Pool) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// categoryColumns são as colunas lidas por scanCategory, na mesma ordem
+const categoryColumns = `id, user_id, parent_id, name, color, icon, archived, created_at, updated_at`
+
+func scanCategory(row pgx.Row) (*model.UserCategory, error) {
+	var category model.UserCategory
+	err := row.Scan(
+		&category.ID,
+		&category.UserID,
+		&category.ParentID,
+		&category.Name,
+		&category.Color,
+		&category.Icon,
+		&category.Archived,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Create insere uma nova categoria. Retorna ErrDuplicate se o usuário já tiver
+// uma categoria com o mesmo nome (sem diferenciar maiúsculas)
+func (r *CategoryRepository) Create(ctx context.Context, category *model.UserCategory) error {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO categories (user_id, parent_id, name, color, icon)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, archived, created_at, updated_at`,
+		category.UserID, category.ParentID, category.Name, category.Color, category.Icon,
+	).Scan(&category.ID, &category.Archived, &category.CreatedAt, &category.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// List retorna as categorias do usuário em ordem alfabética, incluindo as
+// arquivadas apenas quando solicitado
+func (r *CategoryRepository) List(ctx context.Context, userID string, includeArchived bool) ([]*model.UserCategory, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+categoryColumns+` FROM categories
+		 WHERE user_id = $1 AND ($2 OR NOT archived)
+		 ORDER BY lower(name)`,
+		userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*model.UserCategory{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// FindByID busca uma categoria do usuário pelo ID
+func (r *CategoryRepository) FindByID(ctx context.Context, userID, id string) (*model.UserCategory, error) {
+	return scanCategory(r.db.QueryRow(ctx,
+		`SELECT `+categoryColumns+` FROM categories WHERE id = $1 AND user_id = $2`,
+		id, userID))
+}
+
+// FindByName busca uma categoria do usuário pelo nome, sem diferenciar maiúsculas
+func (r *CategoryRepository) FindByName(ctx context.Context, userID, name string) (*model.UserCategory, error) {
+	return scanCategory(r.db.QueryRow(ctx,
+		`SELECT `+categoryColumns+` FROM categories WHERE user_id = $1 AND lower(name) = lower($2)`,
+		userID, name))
+}
+
+// Update grava as alterações da categoria. Retorna ErrDuplicate se o novo nome
+// já estiver em uso pelo usuário
+func (r *CategoryRepository) Update(ctx context.Context, category *model.UserCategory) error {
+	err := r.db.QueryRow(ctx,
+		`UPDATE categories
+		 SET parent_id = $1, name = $2, color = $3, icon = $4, archived = $5, updated_at = CURRENT_TIMESTAMP
+		 WHERE id = $6 AND user_id = $7
+		 RETURNING updated_at`,
+		category.ParentID, category.Name, category.Color, category.Icon, category.Archived, category.ID, category.UserID,
+	).Scan(&category.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Delete remove uma categoria. Retorna ErrInUse se alguma despesa ou
+// subcategoria ainda a usar
+func (r *CategoryRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM categories WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrInUse
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
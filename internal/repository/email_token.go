@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EmailTokenRepository gerencia a persistência dos tokens enviados por email
+type EmailTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewEmailTokenRepository cria uma nova instância do repositório de tokens de email
+func NewEmailTokenRepository(db *pgxpool.Pool) *EmailTokenRepository {
+	return &EmailTokenRepository{db: db}
+}
+
+// Create persiste um novo token
+func (r *EmailTokenRepository) Create(ctx context.Context, token *model.EmailToken) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO email_tokens (user_id, email, purpose, token_hash, expires_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at`,
+		token.UserID, token.Email, token.Purpose, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// FindByHash busca um token pelo hash e pela finalidade
+func (r *EmailTokenRepository) FindByHash(ctx context.Context, tokenHash string, purpose model.EmailTokenPurpose) (*model.EmailToken, error) {
+	var token model.EmailToken
+	err := r.db.QueryRow(ctx,
+		`SELECT id, user_id, email, purpose, token_hash, expires_at, used_at, created_at
+		 FROM email_tokens WHERE token_hash = $1 AND purpose = $2`,
+		tokenHash, purpose,
+	).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Email,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marca o token como utilizado. Retorna false se ele já havia sido utilizado
+func (r *EmailTokenRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		`UPDATE email_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`,
+		time.Now(), id,
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// InvalidateForUser invalida os tokens pendentes de um usuário para a finalidade informada
+func (r *EmailTokenRepository) InvalidateForUser(ctx context.Context, userID string, purpose model.EmailTokenPurpose) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE email_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL`,
+		time.Now(), userID, purpose,
+	)
+	return err
+}
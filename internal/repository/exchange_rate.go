@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExchangeRateRepository gerencia o histórico de cotações das moedas
+type ExchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewExchangeRateRepository cria uma nova instância do repositório de cotações
+func NewExchangeRateRepository(db *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// Upsert grava as cotações, substituindo as existentes na mesma moeda e data
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, rates []model.ExchangeRate) error {
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(
+			`INSERT INTO exchange_rates (currency, date, rate) VALUES ($1, $2, $3)
+			 ON CONFLICT (currency, date) DO UPDATE SET rate = EXCLUDED.rate`,
+			rate.Currency, rate.Date, rate.Rate)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// List retorna todas as cotações gravadas
+func (r *ExchangeRateRepository) List(ctx context.Context) ([]model.ExchangeRate, error) {
+	rows, err := r.db.Query(ctx, `SELECT currency, date, rate::text FROM exchange_rates ORDER BY currency, date`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []model.ExchangeRate
+	for rows.Next() {
+		var rate model.ExchangeRate
+		if err := rows.Scan(&rate.Currency, &rate.Date, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"html"
+	"strings"
 	"time"
 
 	"expenseapi/internal/model"
@@ -17,8 +19,11 @@ type ExpenseRepository interface {
 	Create(ctx context.Context, expense *model.Expense) error
 	GetByID(ctx context.Context, id string, userID string) (*model.Expense, error)
 	List(ctx context.Context, userID string, filter *model.ExpenseFilter) ([]*model.Expense, error)
+	ListPage(ctx context.Context, userID string, filter *model.ExpenseFilter, page model.ExpensePageQuery) ([]*model.Expense, error)
 	Update(ctx context.Context, expense *model.Expense) error
 	Delete(ctx context.Context, id string, userID string) error
+	SummarizeGroups(ctx context.Context, userID string, filter *model.ExpenseFilter) ([]model.ExpenseGroupTotal, error)
+	Search(ctx context.Context, userID string, text string, filter *model.ExpenseFilter, limit int) ([]*model.ExpenseSearchResult, error)
 }
 
 // PostgresExpenseRepository gerencia o acesso aos dados de despesas no banco
@@ -31,50 +36,113 @@ func NewExpenseRepository(db *pgxpool.Pool) ExpenseRepository {
 	return &PostgresExpenseRepository{db: db}
 }
 
+// expenseColumns são as colunas lidas nas consultas de despesas, na ordem de
+// expenseFields. O nome da categoria vem da tabela categories e as tags, de
+// expense_tags
+const expenseColumns = `e.id, e.user_id, e.amount, e.currency, e.description, e.merchant, e.notes, c.name, e.category_id,
+	ARRAY(SELECT t.name FROM expense_tags et JOIN tags t ON t.id = et.tag_id WHERE et.expense_id = e.id ORDER BY t.name),
+	e.date, e.created_at, e.updated_at`
+
+// expenseFields retorna os destinos do Scan para as colunas de expenseColumns
+func expenseFields(expense *model.Expense) []interface{} {
+	return []interface{}{
+		&expense.ID,
+		&expense.UserID,
+		&expense.Amount,
+		&expense.Currency,
+		&expense.Description,
+		&expense.Merchant,
+		&expense.Notes,
+		&expense.Category,
+		&expense.CategoryID,
+		&expense.Tags,
+		&expense.Date,
+		&expense.CreatedAt,
+		&expense.UpdatedAt,
+	}
+}
+
 // Create insere uma nova despesa no banco de dados
 func (r *PostgresExpenseRepository) Create(ctx context.Context, expense *model.Expense) error {
 	query := `
-		INSERT INTO expenses (id, user_id, amount, description, category, date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO expenses (id, user_id, amount, currency, description, merchant, notes, category_id, date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	expense.ID = uuid.New().String()
 	expense.CreatedAt = time.Now()
 	expense.UpdatedAt = expense.CreatedAt
 
-	_, err := r.db.Exec(ctx, query,
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query,
 		expense.ID,
 		expense.UserID,
-		expense.Amount,
+		expense.Amount.String(),
+		expense.Currency,
 		expense.Description,
-		expense.Category,
+		expense.Merchant,
+		expense.Notes,
+		expense.CategoryID,
 		expense.Date,
 		expense.CreatedAt,
 		expense.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := setExpenseTags(ctx, tx, expense); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// setExpenseTags substitui as tags da despesa, criando as que o usuário ainda
+// não tem
+func setExpenseTags(ctx context.Context, tx pgx.Tx, expense *model.Expense) error {
+	if expense.Tags == nil {
+		expense.Tags = []string{}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM expense_tags WHERE expense_id = $1`, expense.ID); err != nil {
+		return err
+	}
+	if len(expense.Tags) == 0 {
+		return nil
+	}
 
+	_, err := tx.Exec(ctx,
+		`INSERT INTO tags (user_id, name)
+		 SELECT $1, unnest($2::text[])
+		 ON CONFLICT (user_id, name) DO NOTHING`,
+		expense.UserID, expense.Tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO expense_tags (expense_id, tag_id)
+		 SELECT $1, id FROM tags WHERE user_id = $2 AND name = ANY($3)`,
+		expense.ID, expense.UserID, expense.Tags)
 	return err
 }
 
 // GetByID busca uma despesa pelo ID
 func (r *PostgresExpenseRepository) GetByID(ctx context.Context, id string, userID string) (*model.Expense, error) {
 	query := `
-		SELECT id, user_id, amount, description, category, date, created_at, updated_at
-		FROM expenses
-		WHERE id = $1 AND user_id = $2
+		SELECT ` + expenseColumns + `
+		FROM expenses e
+		JOIN categories c ON c.id = e.category_id
+		WHERE e.id = $1 AND e.user_id = $2
 	`
 
 	expense := &model.Expense{}
-	err := r.db.QueryRow(ctx, query, id, userID).Scan(
-		&expense.ID,
-		&expense.UserID,
-		&expense.Amount,
-		&expense.Description,
-		&expense.Category,
-		&expense.Date,
-		&expense.CreatedAt,
-		&expense.UpdatedAt,
-	)
+	err := r.db.QueryRow(ctx, query, id, userID).Scan(expenseFields(expense)...)
 
 	if err == pgx.ErrNoRows {
 		return nil, errors.New("despesa não encontrada")
@@ -83,35 +151,49 @@ func (r *PostgresExpenseRepository) GetByID(ctx context.Context, id string, user
 	return expense, err
 }
 
-// List retorna todas as despesas de um usuário com filtros opcionais
+// List retorna todas as despesas de um usuário com filtros opcionais, das mais
+// recentes para as mais antigas
 func (r *PostgresExpenseRepository) List(ctx context.Context, userID string, filter *model.ExpenseFilter) ([]*model.Expense, error) {
-	query := `
-		SELECT id, user_id, amount, description, category, date, created_at, updated_at
-		FROM expenses
-		WHERE user_id = $1
-	`
-	args := []interface{}{userID}
-	argCount := 2
-
-	if filter != nil {
-		if filter.StartDate != nil {
-			query += ` AND date >= $` + string(rune('0'+argCount))
-			args = append(args, filter.StartDate)
-			argCount++
-		}
-		if filter.EndDate != nil {
-			query += ` AND date <= $` + string(rune('0'+argCount))
-			args = append(args, filter.EndDate)
-			argCount++
-		}
-		if filter.Category != nil {
-			query += ` AND category = $` + string(rune('0'+argCount))
-			args = append(args, filter.Category)
-			argCount++
-		}
+	return r.ListPage(ctx, userID, filter, model.ExpensePageQuery{Sort: model.ExpenseSortDate, Desc: true})
+}
+
+// expenseSortColumns associa cada campo de ordenação à coluna e ao tipo usado
+// para comparar o valor guardado no cursor
+var expenseSortColumns = map[string]struct{ column, cast string }{
+	model.ExpenseSortDate:        {"e.date", "date"},
+	model.ExpenseSortAmount:      {"e.amount", "numeric"},
+	model.ExpenseSortCreatedAt:   {"e.created_at", "timestamptz"},
+	model.ExpenseSortDescription: {"e.description", "text"},
+}
+
+// ListPage retorna uma página das despesas do usuário, ordenada pelo campo
+// pedido e desempatada pelo ID. A página começa depois de page.After e tem no
+// máximo page.Limit despesas; sem limite, todas são retornadas
+func (r *PostgresExpenseRepository) ListPage(ctx context.Context, userID string, filter *model.ExpenseFilter, page model.ExpensePageQuery) ([]*model.Expense, error) {
+	sort, ok := expenseSortColumns[page.Sort]
+	if !ok {
+		return nil, errors.New("ordenação inválida: " + page.Sort)
+	}
+	comparison := ">"
+	if page.Desc {
+		comparison = "<"
 	}
 
-	query += ` ORDER BY date DESC`
+	q := NewQueryBuilder(`
+		SELECT ` + expenseColumns + `
+		FROM expenses e
+		JOIN categories c ON c.id = e.category_id
+	`)
+	applyExpenseFilter(q, userID, filter)
+
+	// Keyset: continua a partir da última despesa, comparando o par
+	// (campo, id) para que despesas com o mesmo valor não sejam puladas
+	if page.After != nil {
+		q.Where(`(`+sort.column+`, e.id) `+comparison+` (?::`+sort.cast+`, ?::uuid)`, page.After.Value, page.After.ID)
+	}
+
+	q.OrderBy(sort.column, page.Desc).OrderBy("e.id", page.Desc).Limit(page.Limit)
+	query, args := q.Build()
 
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -119,42 +201,171 @@ func (r *PostgresExpenseRepository) List(ctx context.Context, userID string, fil
 	}
 	defer rows.Close()
 
-	var expenses []*model.Expense
+	expenses := []*model.Expense{}
 	for rows.Next() {
 		expense := &model.Expense{}
-		err := rows.Scan(
-			&expense.ID,
-			&expense.UserID,
-			&expense.Amount,
-			&expense.Description,
-			&expense.Category,
-			&expense.Date,
-			&expense.CreatedAt,
-			&expense.UpdatedAt,
-		)
-		if err != nil {
+		if err := rows.Scan(expenseFields(expense)...); err != nil {
 			return nil, err
 		}
 		expenses = append(expenses, expense)
 	}
 
-	return expenses, nil
+	return expenses, rows.Err()
+}
+
+// searchConfig é a configuração de busca textual em português que ignora
+// acentos, criada em scripts/init.sql
+const searchConfig = "portuguese_unaccent"
+
+// Marcadores usados pelo ts_headline no lugar de <mark> e </mark>, para que o
+// trecho possa ser escapado antes de receber as marcações. São caracteres de uso
+// privado do Unicode, que não aparecem em textos digitados
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// highlightOptions limita cada trecho a alguns termos em volta das palavras
+// encontradas, com até dois trechos por campo
+const highlightOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", ` +
+	`MinWords=8, MaxWords=20, MaxFragments=2, FragmentDelimiter=" … "`
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// Search busca as despesas do usuário pelo texto na descrição, no
+// estabelecimento e nas observações, com radicais do português e sem diferenciar
+// acentos. O texto aceita a sintaxe de buscadores ("aspas", OR, -termo). As
+// despesas vêm da mais relevante para a menos relevante, com os trechos
+// encontrados em cada campo
+func (r *PostgresExpenseRepository) Search(ctx context.Context, userID string, text string, filter *model.ExpenseFilter, limit int) ([]*model.ExpenseSearchResult, error) {
+	headline := func(column string) string {
+		return `CASE WHEN to_tsvector('` + searchConfig + `', ` + column + `) @@ tsq
+			THEN ts_headline('` + searchConfig + `', ` + column + `, tsq, $2) ELSE '' END`
+	}
+
+	q := NewQueryBuilder(`
+		SELECT `+expenseColumns+`, ts_rank_cd(e.search_vector, tsq) AS rank,
+			`+headline("e.description")+`,
+			`+headline("e.merchant")+`,
+			`+headline("e.notes")+`
+		FROM expenses e
+		JOIN categories c ON c.id = e.category_id
+		CROSS JOIN websearch_to_tsquery('`+searchConfig+`', $1) AS tsq
+	`, text, highlightOptions)
+	applyExpenseFilter(q, userID, filter)
+	q.Where("e.search_vector @@ tsq")
+	q.OrderBy("rank", true).OrderBy("e.date", true).OrderBy("e.id", true).Limit(limit)
+	query, args := q.Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*model.ExpenseSearchResult{}
+	for rows.Next() {
+		result := &model.ExpenseSearchResult{}
+		highlights := &result.Highlights
+		fields := append(expenseFields(&result.Expense), &result.Rank, &highlights.Description, &highlights.Merchant, &highlights.Notes)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		highlights.Description = highlight(highlights.Description)
+		highlights.Merchant = highlight(highlights.Merchant)
+		highlights.Notes = highlight(highlights.Notes)
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// highlight escapa o trecho como HTML e troca os marcadores por <mark>
+func highlight(snippet string) string {
+	return highlightReplacer.Replace(html.EscapeString(snippet))
+}
+
+// applyExpenseFilter acrescenta as condições do filtro e a do usuário. A
+// consulta deve usar os aliases e (expenses) e c (categories). O filtro de
+// categorias inclui as despesas de todas as subcategorias; o de tags espera
+// nomes já normalizados e sem repetição
+func applyExpenseFilter(q *QueryBuilder, userID string, filter *model.ExpenseFilter) {
+	q.Equal("e.user_id", userID)
+	if filter == nil {
+		return
+	}
+
+	q.Range("e.date", filter.StartDate, filter.EndDate)
+	q.Range("e.amount", moneyArg(filter.MinAmount), moneyArg(filter.MaxAmount))
+	if filter.CreatedAfter != nil {
+		q.Where("e.created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.UpdatedAfter != nil {
+		q.Where("e.updated_at > ?", *filter.UpdatedAfter)
+	}
+	if filter.Query != "" {
+		q.Where("e.description ILIKE ?", "%"+likeEscaper.Replace(filter.Query)+"%")
+	}
+	if len(filter.Categories) > 0 {
+		names := make([]string, len(filter.Categories))
+		for i, category := range filter.Categories {
+			names[i] = strings.ToLower(string(category))
+		}
+		q.Where(`e.category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE user_id = ? AND lower(name) = ANY(?::text[])
+				UNION
+				SELECT child.id FROM categories child JOIN tree ON child.parent_id = tree.id
+			)
+			SELECT id FROM tree
+		)`, userID, names)
+	}
+	if len(filter.Tags) > 0 {
+		tags := q.Arg(filter.Tags)
+		tagged := `(SELECT COUNT(DISTINCT t.name) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+			WHERE et.expense_id = e.id AND t.name = ANY(` + tags + `::text[]))`
+		if filter.MatchAllTags {
+			q.Where(tagged + ` = cardinality(` + tags + `::text[])`)
+		} else {
+			q.Where(tagged + ` > 0`)
+		}
+	}
+}
+
+// likeEscaper escapa os curingas do LIKE para que o texto buscado seja literal
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// moneyArg converte um valor opcional para o parâmetro NUMERIC da consulta
+func moneyArg(value *model.Money) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.String()
 }
 
 // Update atualiza uma despesa existente
 func (r *PostgresExpenseRepository) Update(ctx context.Context, expense *model.Expense) error {
 	query := `
 		UPDATE expenses
-		SET amount = $1, description = $2, category = $3, date = $4, updated_at = $5
-		WHERE id = $6 AND user_id = $7
+		SET amount = $1, currency = $2, description = $3, merchant = $4, notes = $5, category_id = $6, date = $7, updated_at = $8
+		WHERE id = $9 AND user_id = $10
 	`
 
 	expense.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, query,
-		expense.Amount,
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query,
+		expense.Amount.String(),
+		expense.Currency,
 		expense.Description,
-		expense.Category,
+		expense.Merchant,
+		expense.Notes,
+		expense.CategoryID,
 		expense.Date,
 		expense.UpdatedAt,
 		expense.ID,
@@ -169,7 +380,10 @@ func (r *PostgresExpenseRepository) Update(ctx context.Context, expense *model.E
 		return errors.New("despesa não encontrada")
 	}
 
-	return nil
+	if err := setExpenseTags(ctx, tx, expense); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // Delete remove uma despesa do banco de dados
@@ -187,3 +401,35 @@ func (r *PostgresExpenseRepository) Delete(ctx context.Context, id string, userI
 
 	return nil
 }
+
+// SummarizeGroups soma as despesas por categoria, moeda e data. A soma é feita
+// em NUMERIC no banco, sem arredondamentos; a conversão para a moeda base fica
+// com o serviço, que precisa da cotação de cada data
+func (r *PostgresExpenseRepository) SummarizeGroups(ctx context.Context, userID string, filter *model.ExpenseFilter) ([]model.ExpenseGroupTotal, error) {
+	q := NewQueryBuilder(`
+		SELECT c.id, c.name, e.currency, e.date, SUM(e.amount), COUNT(*)
+		FROM expenses e
+		JOIN categories c ON c.id = e.category_id
+	`)
+	applyExpenseFilter(q, userID, filter)
+	q.GroupBy("c.id", "c.name", "e.currency", "e.date").
+		OrderBy("c.name", false).OrderBy("e.currency", false).OrderBy("e.date", false)
+	query, args := q.Build()
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []model.ExpenseGroupTotal
+	for rows.Next() {
+		var group model.ExpenseGroupTotal
+		if err := rows.Scan(&group.CategoryID, &group.Category, &group.Currency, &group.Date, &group.Total, &group.Count); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
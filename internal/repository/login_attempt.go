@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginAttemptRepository persiste os contadores de falhas e os eventos de login,
+// permitindo que o limite seja compartilhado entre instâncias da API
+type LoginAttemptRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewLoginAttemptRepository cria uma nova instância do repositório de tentativas de login
+func NewLoginAttemptRepository(db *pgxpool.Pool) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// Get busca o contador da chave. Retorna nil se não houver falhas registradas
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := r.db.QueryRow(ctx,
+		`SELECT key, failures, locked_until, updated_at FROM login_attempts WHERE key = $1`,
+		key,
+	).Scan(&attempt.Key, &attempt.Failures, &attempt.LockedUntil, &attempt.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Increment soma uma falha de forma atômica, recomeçando a contagem se a última
+// falha for anterior a since
+func (r *LoginAttemptRepository) Increment(ctx context.Context, key string, since time.Time) (int, error) {
+	var failures int
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO login_attempts (key, failures, updated_at)
+		 VALUES ($1, 1, NOW())
+		 ON CONFLICT (key) DO UPDATE SET
+		   failures = CASE WHEN login_attempts.updated_at < $2 THEN 1 ELSE login_attempts.failures + 1 END,
+		   updated_at = NOW()
+		 RETURNING failures`,
+		key, since,
+	).Scan(&failures)
+	return failures, err
+}
+
+// Lock bloqueia a chave até o instante informado
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE login_attempts SET locked_until = $1 WHERE key = $2`,
+		until, key,
+	)
+	return err
+}
+
+// Reset remove o contador da chave
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
+
+// RecordEvent registra um evento de login
+func (r *LoginAttemptRepository) RecordEvent(ctx context.Context, event model.LoginEvent) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO login_events (email, ip, user_agent, result, created_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		event.Email, event.IP, event.UserAgent, event.Result, event.CreatedAt,
+	)
+	return err
+}
+
+// Purge remove contadores sem falhas recentes e que não estejam bloqueados
+func (r *LoginAttemptRepository) Purge(ctx context.Context, before time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM login_attempts
+		 WHERE updated_at < $1 AND (locked_until IS NULL OR locked_until < NOW())`,
+		before,
+	)
+	return err
+}
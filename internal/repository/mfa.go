@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MFARepository gerencia a persistência da autenticação em dois fatores
+type MFARepository struct {
+	db *pgxpool.Pool
+}
+
+// NewMFARepository cria uma nova instância do repositório de 2FA
+func NewMFARepository(db *pgxpool.Pool) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// SetPendingSecret grava um novo segredo TOTP ainda não confirmado
+func (r *MFARepository) SetPendingSecret(ctx context.Context, userID, secret string) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET totp_secret = $1, totp_last_step = NULL, mfa_enabled_at = NULL
+		 WHERE id = $2 AND mfa_enabled_at IS NULL`,
+		secret, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Enable ativa o 2FA e substitui os códigos de recuperação em uma transação
+func (r *MFARepository) Enable(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`UPDATE users SET mfa_enabled_at = $1, totp_last_step = $2
+		 WHERE id = $3 AND totp_secret IS NOT NULL AND mfa_enabled_at IS NULL`,
+		time.Now(), step, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`,
+			userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClaimStep registra o uso do código TOTP do intervalo informado. Retorna
+// false se um código desse intervalo (ou posterior) já foi usado
+func (r *MFARepository) ClaimStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET totp_last_step = $1
+		 WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)`,
+		step, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode consome um código de recuperação. Retorna false se ele
+// não existir ou já tiver sido utilizado
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		`UPDATE mfa_recovery_codes SET used_at = $1
+		 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`,
+		time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
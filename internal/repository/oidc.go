@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OIDCRepository gerencia os logins OIDC em andamento e as identidades vinculadas
+type OIDCRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewOIDCRepository cria uma nova instância do repositório OIDC
+func NewOIDCRepository(db *pgxpool.Pool) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// CreateState persiste um login em andamento, removendo os já expirados
+func (r *OIDCRepository) CreateState(ctx context.Context, state *model.OIDCState) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM oidc_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+
+	return r.db.QueryRow(ctx,
+		`INSERT INTO oidc_states (state_hash, provider, code_verifier, nonce, expires_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING created_at`,
+		state.StateHash, state.Provider, state.CodeVerifier, state.Nonce, state.ExpiresAt,
+	).Scan(&state.CreatedAt)
+}
+
+// ConsumeState busca e remove o login em andamento, garantindo uso único
+func (r *OIDCRepository) ConsumeState(ctx context.Context, stateHash, provider string) (*model.OIDCState, error) {
+	var state model.OIDCState
+	err := r.db.QueryRow(ctx,
+		`DELETE FROM oidc_states WHERE state_hash = $1 AND provider = $2
+		 RETURNING state_hash, provider, code_verifier, nonce, expires_at, created_at`,
+		stateHash, provider,
+	).Scan(&state.StateHash, &state.Provider, &state.CodeVerifier, &state.Nonce, &state.ExpiresAt, &state.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FindIdentity busca a identidade vinculada à conta do provedor
+func (r *OIDCRepository) FindIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.QueryRow(ctx,
+		`SELECT id, user_id, provider, subject, email, created_at
+		 FROM user_identities WHERE provider = $1 AND subject = $2`,
+		provider, subject,
+	).Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity vincula uma conta do provedor a um usuário
+func (r *OIDCRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO user_identities (user_id, provider, subject, email)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at`,
+		identity.UserID, identity.Provider, identity.Subject, identity.Email,
+	).Scan(&identity.ID, &identity.CreatedAt)
+}
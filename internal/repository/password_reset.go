@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PasswordResetRepository gerencia a persistência dos tokens de redefinição de senha
+type PasswordResetRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPasswordResetRepository cria uma nova instância do repositório de redefinição de senha
+func NewPasswordResetRepository(db *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create persiste um novo token de redefinição
+func (r *PasswordResetRepository) Create(ctx context.Context, token *model.PasswordResetToken) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, created_at`,
+		token.UserID, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// FindByHash busca um token de redefinição pelo hash
+func (r *PasswordResetRepository) FindByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	err := r.db.QueryRow(ctx,
+		`SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		 FROM password_reset_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marca o token como utilizado. Retorna false se ele já havia sido utilizado
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		`UPDATE password_reset_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`,
+		time.Now(), id,
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// InvalidateForUser invalida todos os tokens pendentes de um usuário
+func (r *PasswordResetRepository) InvalidateForUser(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`,
+		time.Now(), userID,
+	)
+	return err
+}
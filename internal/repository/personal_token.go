@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PersonalTokenRepository gerencia a persistência dos tokens de acesso pessoal
+type PersonalTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPersonalTokenRepository cria uma nova instância do repositório de tokens pessoais
+func NewPersonalTokenRepository(db *pgxpool.Pool) *PersonalTokenRepository {
+	return &PersonalTokenRepository{db: db}
+}
+
+const personalTokenColumns = "id, user_id, name, token_hash, hint, scopes, expires_at, last_used_at, created_at"
+
+func scanPersonalToken(row pgx.Row) (*model.PersonalToken, error) {
+	var token model.PersonalToken
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, &token.Hint,
+		&token.Scopes, &token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Create persiste um novo token pessoal
+func (r *PersonalTokenRepository) Create(ctx context.Context, token *model.PersonalToken) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO personal_access_tokens (user_id, name, token_hash, hint, scopes, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, created_at`,
+		token.UserID, token.Name, token.TokenHash, token.Hint, token.Scopes, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// FindByHash busca um token pessoal pelo hash. Tokens de contas desativadas
+// ou com exclusão agendada não são retornados
+func (r *PersonalTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.PersonalToken, error) {
+	token, err := scanPersonalToken(r.db.QueryRow(ctx,
+		`SELECT `+personalTokenColumns+` FROM personal_access_tokens
+		 WHERE token_hash = $1
+		   AND user_id IN (SELECT id FROM users WHERE disabled_at IS NULL AND deletion_scheduled_at IS NULL)`,
+		tokenHash,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// ListByUser lista os tokens pessoais de um usuário, do mais recente ao mais antigo
+func (r *PersonalTokenRepository) ListByUser(ctx context.Context, userID string) ([]*model.PersonalToken, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+personalTokenColumns+` FROM personal_access_tokens
+		 WHERE user_id = $1 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*model.PersonalToken{}
+	for rows.Next() {
+		token, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// Delete remove um token pessoal do usuário
+func (r *PersonalTokenRepository) Delete(ctx context.Context, id, userID string) error {
+	result, err := r.db.Exec(ctx,
+		`DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteAllForUser remove todos os tokens pessoais do usuário
+func (r *PersonalTokenRepository) DeleteAllForUser(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM personal_access_tokens WHERE user_id = $1`, userID)
+	return err
+}
+
+// TouchLastUsed registra o uso do token, gravando no máximo uma vez por minuto
+// para não gerar uma escrita a cada requisição
+func (r *PersonalTokenRepository) TouchLastUsed(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE personal_access_tokens SET last_used_at = NOW()
+		 WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`,
+		id,
+	)
+	return err
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder monta uma consulta SQL com condições, agrupamento, ordenação e
+// limite, numerando os parâmetros ($1, $2, ...) na ordem em que são
+// acrescentados. As condições são combinadas com AND
+type QueryBuilder struct {
+	base    string
+	where   []string
+	groupBy []string
+	orderBy []string
+	limit   int
+	args    []interface{}
+}
+
+// NewQueryBuilder cria um construtor a partir do início da consulta, sem WHERE
+// (por exemplo, "SELECT ... FROM expenses e JOIN categories c ON ..."). O início
+// pode usar os marcadores $1 a $n dos argumentos informados; os demais
+// parâmetros são numerados a partir deles
+func NewQueryBuilder(base string, args ...interface{}) *QueryBuilder {
+	return &QueryBuilder{base: strings.TrimSpace(base), args: append([]interface{}(nil), args...)}
+}
+
+// Arg acrescenta um parâmetro e retorna o marcador correspondente, para
+// condições que usam o mesmo valor mais de uma vez
+func (q *QueryBuilder) Arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// Where acrescenta uma condição. Cada ? é substituído pelo marcador do valor
+// correspondente, na ordem; a quantidade de ? deve ser igual à de valores
+func (q *QueryBuilder) Where(condition string, values ...interface{}) *QueryBuilder {
+	parts := strings.Split(condition, "?")
+	if len(parts)-1 != len(values) {
+		panic("query: condição com " + strconv.Itoa(len(parts)-1) + " marcadores e " + strconv.Itoa(len(values)) + " valores: " + condition)
+	}
+
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for i, value := range values {
+		b.WriteString(q.Arg(value))
+		b.WriteString(parts[i+1])
+	}
+	q.where = append(q.where, b.String())
+	return q
+}
+
+// Equal acrescenta a condição column = valor
+func (q *QueryBuilder) Equal(column string, value interface{}) *QueryBuilder {
+	return q.Where(column+" = ?", value)
+}
+
+// In acrescenta a condição column IN (...), com um parâmetro por valor. Uma
+// lista vazia não corresponde a nenhuma linha
+func (q *QueryBuilder) In(column string, values ...interface{}) *QueryBuilder {
+	if len(values) == 0 {
+		q.where = append(q.where, "FALSE")
+		return q
+	}
+
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = q.Arg(value)
+	}
+	q.where = append(q.where, column+" IN ("+strings.Join(placeholders, ", ")+")")
+	return q
+}
+
+// Range acrescenta os limites inclusivos column >= from e column <= to. Um
+// limite nulo (inclusive um ponteiro nulo) deixa o intervalo aberto daquele lado
+func (q *QueryBuilder) Range(column string, from, to interface{}) *QueryBuilder {
+	if !isNil(from) {
+		q.Where(column+" >= ?", from)
+	}
+	if !isNil(to) {
+		q.Where(column+" <= ?", to)
+	}
+	return q
+}
+
+// GroupBy acrescenta colunas ao agrupamento
+func (q *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
+	q.groupBy = append(q.groupBy, columns...)
+	return q
+}
+
+// OrderBy acrescenta uma coluna à ordenação. A coluna é inserida na consulta
+// como está e nunca deve vir do cliente sem passar por uma lista permitida
+func (q *QueryBuilder) OrderBy(column string, desc bool) *QueryBuilder {
+	if desc {
+		column += " DESC"
+	} else {
+		column += " ASC"
+	}
+	q.orderBy = append(q.orderBy, column)
+	return q
+}
+
+// Limit limita a quantidade de linhas; zero ou negativo mantém a consulta sem
+// limite
+func (q *QueryBuilder) Limit(limit int) *QueryBuilder {
+	q.limit = limit
+	return q
+}
+
+// Build retorna a consulta e os argumentos na ordem dos marcadores
+func (q *QueryBuilder) Build() (string, []interface{}) {
+	var b strings.Builder
+	b.WriteString(q.base)
+	if len(q.where) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(q.where, " AND "))
+	}
+	if len(q.groupBy) > 0 {
+		b.WriteString(" GROUP BY ")
+		b.WriteString(strings.Join(q.groupBy, ", "))
+	}
+	if len(q.orderBy) > 0 {
+		b.WriteString(" ORDER BY ")
+		b.WriteString(strings.Join(q.orderBy, ", "))
+	}
+
+	args := make([]interface{}, len(q.args), len(q.args)+1)
+	copy(args, q.args)
+	if q.limit > 0 {
+		args = append(args, q.limit)
+		b.WriteString(" LIMIT $")
+		b.WriteString(strconv.Itoa(len(args)))
+	}
+	return b.String(), args
+}
+
+// isNil trata ponteiros, slices e mapas nulos guardados em interface{} como nulos
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
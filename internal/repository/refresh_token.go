@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RefreshTokenRepository gerencia a persistência dos refresh tokens
+type RefreshTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRefreshTokenRepository cria uma nova instância do repositório de refresh tokens
+func NewRefreshTokenRepository(db *pgxpool.Pool) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create persiste um novo refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at`,
+		token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// FindByHash busca um refresh token pelo hash
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.QueryRow(ctx,
+		`SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		 FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed marca o token como utilizado. Retorna false se ele já havia sido
+// utilizado ou revogado, o que indica reutilização
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens SET used_at = $1
+		 WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL`,
+		time.Now(), id,
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// RevokeFamily revoga todos os tokens de uma mesma família
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1
+		 WHERE family_id = $2 AND revoked_at IS NULL`,
+		time.Now(), familyID,
+	)
+	return err
+}
+
+// RevokeAllForUser revoga todos os refresh tokens de um usuário
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1
+		 WHERE user_id = $2 AND revoked_at IS NULL`,
+		time.Now(), userID,
+	)
+	return err
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevocationRepository persiste os tokens revogados
+type RevocationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRevocationRepository cria uma nova instância do repositório de revogações
+func NewRevocationRepository(db *pgxpool.Pool) *RevocationRepository {
+	return &RevocationRepository{db: db}
+}
+
+// RevokeToken registra a revogação de um token pelo jti
+func (r *RevocationRepository) RevokeToken(ctx context.Context, token model.RevokedToken) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (jti) DO NOTHING`,
+		token.JTI, token.UserID, token.ExpiresAt,
+	)
+	return err
+}
+
+// RevokeUser registra a revogação de todos os tokens de um usuário
+func (r *RevocationRepository) RevokeUser(ctx context.Context, revocation model.UserRevocation) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO user_token_revocations (user_id, revoked_before, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before, expires_at = EXCLUDED.expires_at`,
+		revocation.UserID, revocation.RevokedBefore, revocation.ExpiresAt,
+	)
+	return err
+}
+
+// RevokeSession registra a revogação dos tokens de uma sessão
+func (r *RevocationRepository) RevokeSession(ctx context.Context, session model.RevokedSession) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO revoked_sessions (session_id, user_id, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (session_id) DO UPDATE SET expires_at = EXCLUDED.expires_at`,
+		session.SessionID, session.UserID, session.ExpiresAt,
+	)
+	return err
+}
+
+// ListActive retorna as revogações ainda não expiradas
+func (r *RevocationRepository) ListActive(ctx context.Context, now time.Time) ([]model.RevokedToken, []model.UserRevocation, []model.RevokedSession, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT jti, user_id, expires_at FROM revoked_tokens WHERE expires_at > $1`, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	var tokens []model.RevokedToken
+	for rows.Next() {
+		var token model.RevokedToken
+		if err := rows.Scan(&token.JTI, &token.UserID, &token.ExpiresAt); err != nil {
+			return nil, nil, nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err = r.db.Query(ctx,
+		`SELECT user_id, revoked_before, expires_at FROM user_token_revocations WHERE expires_at > $1`, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	var users []model.UserRevocation
+	for rows.Next() {
+		var user model.UserRevocation
+		if err := rows.Scan(&user.UserID, &user.RevokedBefore, &user.ExpiresAt); err != nil {
+			return nil, nil, nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err = r.db.Query(ctx,
+		`SELECT session_id, user_id, expires_at FROM revoked_sessions WHERE expires_at > $1`, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	var sessions []model.RevokedSession
+	for rows.Next() {
+		var session model.RevokedSession
+		if err := rows.Scan(&session.SessionID, &session.UserID, &session.ExpiresAt); err != nil {
+			return nil, nil, nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return tokens, users, sessions, rows.Err()
+}
+
+// PurgeExpired remove as revogações expiradas
+func (r *RevocationRepository) PurgeExpired(ctx context.Context, now time.Time) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= $1`, now); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(ctx, `DELETE FROM user_token_revocations WHERE expires_at <= $1`, now); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx, `DELETE FROM revoked_sessions WHERE expires_at <= $1`, now)
+	return err
+}
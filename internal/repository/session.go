@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const sessionColumns = "id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at"
+
+// SessionRepository gerencia a persistência das sessões de login
+type SessionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSessionRepository cria uma nova instância do repositório de sessões
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create persiste uma nova sessão
+func (r *SessionRepository) Create(ctx context.Context, session *model.Session) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO sessions (id, user_id, user_agent, ip)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING created_at, last_seen_at`,
+		session.ID, session.UserID, session.UserAgent, session.IP,
+	).Scan(&session.CreatedAt, &session.LastSeenAt)
+}
+
+// ListActive retorna as sessões não revogadas do usuário que ainda podem ser
+// renovadas, da mais recente para a mais antiga. Uma sessão cujo refresh token
+// atual expirou já terminou, mesmo sem ter sido revogada
+func (r *SessionRepository) ListActive(ctx context.Context, userID string) ([]model.Session, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+sessionColumns+` FROM sessions
+		 WHERE user_id = $1 AND revoked_at IS NULL
+		   AND EXISTS (
+		       SELECT 1 FROM refresh_tokens t
+		       WHERE t.family_id = sessions.id AND t.used_at IS NULL
+		         AND t.revoked_at IS NULL AND t.expires_at > $2
+		   )
+		 ORDER BY last_seen_at DESC`,
+		userID, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch registra o uso da sessão, atualizando o último acesso e o IP
+func (r *SessionRepository) Touch(ctx context.Context, id, ip string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE sessions SET last_seen_at = $1, ip = COALESCE(NULLIF($2, ''), ip)
+		 WHERE id = $3 AND revoked_at IS NULL`,
+		time.Now(), ip, id,
+	)
+	return err
+}
+
+// Revoke marca a sessão do usuário como encerrada. Retorna sql.ErrNoRows se
+// ela não existir, pertencer a outro usuário ou já tiver sido encerrada
+func (r *SessionRepository) Revoke(ctx context.Context, id, userID string) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE sessions SET revoked_at = $1
+		 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`,
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeAllForUser encerra todas as sessões do usuário e retorna os IDs das
+// sessões encerradas
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE sessions SET revoked_at = $1
+		 WHERE user_id = $2 AND revoked_at IS NULL
+		 RETURNING id`,
+		time.Now(), userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func scanSession(row pgx.Row) (*model.Session, error) {
+	var session model.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
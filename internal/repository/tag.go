@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"expenseapi/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagRepository gerencia as tags das despesas dos usuários
+type TagRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewTagRepository cria uma nova instância do repositório de tags
+func NewTagRepository(db *pgxpool.Pool) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// ListUsage retorna as tags usadas em ao menos uma despesa do usuário, das mais
+// usadas para as menos usadas
+func (r *TagRepository) ListUsage(ctx context.Context, userID string) ([]model.TagUsage, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT t.name, COUNT(*)
+		 FROM tags t
+		 JOIN expense_tags et ON et.tag_id = t.id
+		 WHERE t.user_id = $1
+		 GROUP BY t.name
+		 ORDER BY COUNT(*) DESC, t.name`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []model.TagUsage{}
+	for rows.Next() {
+		var tag model.TagUsage
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"expenseapi/internal/model"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrDuplicate indica violação de unicidade ao gravar um registro
+var ErrDuplicate = errors.New("registro duplicado")
+
 type UserRepository struct {
 	db *pgxpool.Pool
 }
@@ -25,16 +30,34 @@ func (r *UserRepository) Create(ctx context.Context, email, passwordHash string)
 		`INSERT INTO users (email, password_hash) VALUES ($1, $2) RETURNING id`,
 		email, passwordHash).Scan(&id)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return "", ErrDuplicate
+		}
 		return "", err
 	}
 	return id, nil
 }
 
-func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+// userColumns são as colunas lidas por scanUser, na mesma ordem
+const userColumns = `id, email, password_hash, role, email_verified_at, totp_secret, mfa_enabled_at, disabled_at, deletion_scheduled_at, base_currency, created_at, updated_at`
+
+func scanUser(row pgx.Row) (*model.User, error) {
 	var user model.User
-	err := r.db.QueryRow(ctx,
-		`SELECT id, email, password_hash, created_at, updated_at FROM users WHERE email = $1`,
-		email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.TOTPSecret,
+		&user.MFAEnabledAt,
+		&user.DisabledAt,
+		&user.DeletionScheduledAt,
+		&user.BaseCurrency,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, sql.ErrNoRows
@@ -44,16 +67,162 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.
 	return &user, nil
 }
 
+// FindByEmail busca o usuário pelo email, sem diferenciar maiúsculas
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	return scanUser(r.db.QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE lower(email) = lower($1)`,
+		email))
+}
+
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
-	var user model.User
-	err := r.db.QueryRow(ctx,
-		`SELECT id, email, password_hash, created_at, updated_at FROM users WHERE id = $1`,
-		id).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	return scanUser(r.db.QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = $1`,
+		id))
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET password_hash = $1 WHERE id = $2`,
+		passwordHash, id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, sql.ErrNoRows
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateEmail altera o email do usuário, já confirmado por ele. Retorna
+// ErrDuplicate se o email já estiver em uso
+func (r *UserRepository) UpdateEmail(ctx context.Context, id, email string) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET email = $1, email_verified_at = CURRENT_TIMESTAMP WHERE id = $2`,
+		email, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicate
 		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkEmailVerified registra a confirmação do email do usuário
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id string) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP) WHERE id = $1`,
+		id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// List lista os usuários em ordem de criação, com paginação
+func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+userColumns+` FROM users ORDER BY created_at, id LIMIT $1 OFFSET $2`,
+		limit, offset)
+	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// UpdateRole altera o papel do usuário
+func (r *UserRepository) UpdateRole(ctx context.Context, id string, role model.Role) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+		role, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetDisabled desativa ou reativa a conta do usuário
+func (r *UserRepository) SetDisabled(ctx context.Context, id string, disabled bool) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, CURRENT_TIMESTAMP) ELSE NULL END,
+		     updated_at = CURRENT_TIMESTAMP
+		 WHERE id = $2`,
+		disabled, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ScheduleDeletion agenda a exclusão definitiva da conta para a data informada
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, id string, at time.Time) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET deletion_scheduled_at = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+		at, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateBaseCurrency altera a moeda base do usuário
+func (r *UserRepository) UpdateBaseCurrency(ctx context.Context, id, currency string) error {
+	result, err := r.db.Exec(ctx,
+		`UPDATE users SET base_currency = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+		currency, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CancelDeletion cancela a exclusão agendada da conta
+func (r *UserRepository) CancelDeletion(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE users SET deletion_scheduled_at = NULL, updated_at = CURRENT_TIMESTAMP
+		 WHERE id = $1 AND deletion_scheduled_at IS NOT NULL`,
+		id)
+	return err
+}
+
+// DeleteScheduled exclui as contas cuja exclusão agendada já venceu. Os dados
+// relacionados são removidos em cascata. Retorna o número de contas excluídas
+func (r *UserRepository) DeleteScheduled(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx,
+		`DELETE FROM users WHERE deletion_scheduled_at <= $1`,
+		now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -18,6 +18,7 @@ func NewServer(authHandler *handler.AuthHandler) *Server {
 	// Configuração das rotas
 	server.router.HandleFunc("POST /api/v1/auth/register", authHandler.Register)
 	server.router.HandleFunc("POST /api/v1/auth/login", authHandler.Login)
+	server.router.HandleFunc("POST /api/v1/auth/refresh", authHandler.Refresh)
 
 	return server
 }
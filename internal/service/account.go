@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/mailer"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+var (
+	ErrUserNotFound      = errors.New("usuário não encontrado")
+	ErrInvalidEmailToken = errors.New("token de confirmação inválido ou expirado")
+	ErrSameEmail         = errors.New("o novo email é igual ao atual")
+)
+
+// AccountService gerencia os dados da conta do usuário autenticado
+type AccountService struct {
+	userRepo    *repository.UserRepository
+	tokenRepo   *repository.EmailTokenRepository
+	authService *AuthService
+	mailer      mailer.Mailer
+	appURL      string
+	tokenTTL    time.Duration
+}
+
+// NewAccountService cria uma nova instância do serviço de conta
+func NewAccountService(userRepo *repository.UserRepository, tokenRepo *repository.EmailTokenRepository, authService *AuthService, mailer mailer.Mailer, appURL string, tokenTTL time.Duration) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		authService: authService,
+		mailer:      mailer,
+		appURL:      appURL,
+		tokenTTL:    tokenTTL,
+	}
+}
+
+// Profile retorna o perfil do usuário
+func (s *AccountService) Profile(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword troca a senha após conferir a atual. Todas as sessões
+// existentes são encerradas e um novo par de tokens é emitido
+func (s *AccountService) ChangePassword(ctx context.Context, userID string, input model.ChangePasswordInput, client model.ClientInfo) (*model.LoginResponse, error) {
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.ComparePassword(input.CurrentPassword); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.authService.ValidatePassword(input.NewPassword, user.Email); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := model.HashPassword(input.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		return nil, err
+	}
+
+	if err := s.authService.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.authService.IssueTokens(ctx, user.ID, client)
+}
+
+// RequestEmailChange envia um link de confirmação para o novo endereço. O
+// email só é alterado depois que o link for confirmado
+func (s *AccountService) RequestEmailChange(ctx context.Context, userID string, input model.ChangeEmailInput) error {
+	input.Email = normalizeEmail(input.Email)
+	user, err := s.Profile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := user.ComparePassword(input.Password); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if strings.EqualFold(user.Email, input.Email) {
+		return ErrSameEmail
+	}
+
+	if _, err := s.userRepo.FindByEmail(ctx, input.Email); err == nil {
+		return ErrUserExists
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	// Apenas a solicitação mais recente permanece válida
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, model.EmailTokenChangeEmail); err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.tokenRepo.Create(ctx, &model.EmailToken{
+		UserID:    user.ID,
+		Email:     input.Email,
+		Purpose:   model.EmailTokenChangeEmail,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/confirm-email?token=%s", s.appURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      input.Email,
+		Subject: "Confirme o seu novo email",
+		Body: fmt.Sprintf(
+			"Recebemos uma solicitação para alterar o email da sua conta para este endereço.\n\n"+
+				"Confirme a alteração em até %s:\n%s\n\n"+
+				"Se você não fez essa solicitação, ignore este email.",
+			s.tokenTTL, link,
+		),
+	})
+}
+
+// ConfirmEmailChange aplica a troca de email a partir do token enviado ao novo endereço
+func (s *AccountService) ConfirmEmailChange(ctx context.Context, input model.ConfirmEmailInput) (*model.User, error) {
+	stored, err := consumeEmailToken(ctx, s.tokenRepo, input.Token, model.EmailTokenChangeEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateEmail(ctx, stored.UserID, normalizeEmail(stored.Email)); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	return s.Profile(ctx, stored.UserID)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRole      = errors.New("papel inválido")
+	ErrCannotChangeSelf = errors.New("administradores não podem desativar ou rebaixar a própria conta")
+)
+
+// AdminService reúne as operações administrativas sobre as contas
+type AdminService struct {
+	userRepo    *repository.UserRepository
+	authService *AuthService
+}
+
+// NewAdminService cria uma nova instância do serviço administrativo
+func NewAdminService(userRepo *repository.UserRepository, authService *AuthService) *AdminService {
+	return &AdminService{
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+// ListUsers lista os usuários com paginação
+func (s *AdminService) ListUsers(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	return s.userRepo.List(ctx, limit, offset)
+}
+
+// SetRole altera o papel do usuário. O novo papel vale a partir dos próximos
+// tokens; as sessões atuais são encerradas para que ele seja aplicado de imediato
+func (s *AdminService) SetRole(ctx context.Context, adminID, userID string, role model.Role) (*model.User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	if adminID == userID && role != model.RoleAdmin {
+		return nil, ErrCannotChangeSelf
+	}
+
+	if err := s.update(userID, func() error { return s.userRepo.UpdateRole(ctx, userID, role) }); err != nil {
+		return nil, err
+	}
+	if err := s.authService.LogoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.FindByID(ctx, userID)
+}
+
+// Disable desativa a conta e revoga todos os tokens do usuário
+func (s *AdminService) Disable(ctx context.Context, adminID, userID string) error {
+	if adminID == userID {
+		return ErrCannotChangeSelf
+	}
+
+	if err := s.update(userID, func() error { return s.userRepo.SetDisabled(ctx, userID, true) }); err != nil {
+		return err
+	}
+
+	return s.authService.LogoutAll(ctx, userID)
+}
+
+// Enable reativa a conta do usuário
+func (s *AdminService) Enable(ctx context.Context, userID string) error {
+	return s.update(userID, func() error { return s.userRepo.SetDisabled(ctx, userID, false) })
+}
+
+// ForceLogout encerra todas as sessões do usuário
+func (s *AdminService) ForceLogout(ctx context.Context, userID string) error {
+	if err := s.update(userID, func() error {
+		_, err := s.userRepo.FindByID(ctx, userID)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return s.authService.LogoutAll(ctx, userID)
+}
+
+// update valida o ID e traduz a ausência do usuário para ErrUserNotFound
+func (s *AdminService) update(userID string, fn func() error) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := fn(); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
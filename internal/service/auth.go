@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"expenseapi/internal/auth"
 	"expenseapi/internal/model"
@@ -13,21 +16,60 @@ import (
 var (
 	ErrInvalidCredentials = errors.New("credenciais inválidas")
 	ErrUserExists         = errors.New("usuário já existe")
+	ErrInvalidRefresh     = errors.New("refresh token inválido")
+	ErrRefreshReused      = errors.New("refresh token reutilizado")
+	ErrTokenRevoked       = errors.New("token revogado")
+	ErrTooManyAttempts    = errors.New("muitas tentativas de login")
+	ErrAccountDisabled    = errors.New("conta desativada")
 )
 
+// RateLimitError indica que o login foi bloqueado temporariamente
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
 type AuthService struct {
-	userRepo   *repository.UserRepository
-	jwtService *auth.JWTService
+	userRepo         *repository.UserRepository
+	refreshRepo      *repository.RefreshTokenRepository
+	jwtService       *auth.JWTService
+	revocations      *auth.RevocationList
+	verification     *VerificationService
+	unverifiedAccess UnverifiedAccess
+	limiter          *auth.LoginLimiter
+	personalTokens   *PersonalTokenService
+	sessions         *SessionService
+	passwordPolicy   *PasswordPolicy
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtService *auth.JWTService) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, refreshRepo *repository.RefreshTokenRepository, jwtService *auth.JWTService, revocations *auth.RevocationList, verification *VerificationService, unverifiedAccess UnverifiedAccess, limiter *auth.LoginLimiter, personalTokens *PersonalTokenService, sessions *SessionService, passwordPolicy *PasswordPolicy) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:         userRepo,
+		refreshRepo:      refreshRepo,
+		jwtService:       jwtService,
+		revocations:      revocations,
+		verification:     verification,
+		unverifiedAccess: unverifiedAccess,
+		limiter:          limiter,
+		personalTokens:   personalTokens,
+		sessions:         sessions,
+		passwordPolicy:   passwordPolicy,
 	}
 }
 
 func (s *AuthService) Register(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
+	input.Email = normalizeEmail(input.Email)
+	if err := s.ValidatePassword(input.Password, input.Email); err != nil {
+		return nil, err
+	}
+
 	// Verifica se o usuário já existe
 	_, err := s.userRepo.FindByEmail(ctx, input.Email)
 	if err == nil {
@@ -43,9 +85,12 @@ func (s *AuthService) Register(ctx context.Context, input model.CreateUserInput)
 		return nil, err
 	}
 
-	// Cria o usuário
+	// Cria o usuário; o índice único do email cobre cadastros simultâneos
 	userID, err := s.userRepo.Create(ctx, input.Email, passwordHash)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrUserExists
+		}
 		return nil, err
 	}
 
@@ -55,11 +100,87 @@ func (s *AuthService) Register(ctx context.Context, input model.CreateUserInput)
 		return nil, err
 	}
 
+	// Envia o link de verificação; uma falha no envio não impede o cadastro,
+	// pois o usuário pode solicitar o reenvio
+	if err := s.verification.Send(ctx, user); err != nil {
+		log.Printf("Erro ao enviar email de verificação para %s: %v", user.Email, err)
+	}
+
 	return user, nil
 }
 
+// normalizeEmail é a forma em que os emails são gravados e comparados: sem
+// espaços nas pontas e em minúsculas
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidatePassword aplica a política de senhas a uma nova senha do usuário
+func (s *AuthService) ValidatePassword(plain, email string) error {
+	return s.passwordPolicy.Validate(plain, email)
+}
+
 func (s *AuthService) Login(ctx context.Context, input model.LoginInput) (*model.LoginResponse, error) {
-	// Busca o usuário pelo email
+	email := normalizeEmail(input.Email)
+	event := model.LoginEvent{Email: email, IP: input.IP, UserAgent: input.UserAgent}
+
+	// Recusa a tentativa enquanto a conta ou o IP estiverem bloqueados
+	retryAfter, err := s.limiter.Check(ctx, email, input.IP)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		event.Result = model.LoginEventBlocked
+		s.limiter.Record(ctx, event)
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	user, err := s.checkCredentials(ctx, input)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			event.Result = model.LoginEventFailure
+			s.limiter.Record(ctx, event)
+			if err := s.limiter.Failure(ctx, email, input.IP); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	event.Result = model.LoginEventSuccess
+	s.limiter.Record(ctx, event)
+	if err := s.limiter.Success(ctx, email); err != nil {
+		return nil, err
+	}
+
+	return s.CompleteLogin(ctx, user, model.ClientInfo{IP: input.IP, UserAgent: input.UserAgent})
+}
+
+// CompleteLogin conclui um login cujo primeiro fator já foi confirmado (senha
+// ou provedor externo), aplicando a política de email não verificado e o 2FA.
+// client identifica o dispositivo na sessão criada
+func (s *AuthService) CompleteLogin(ctx context.Context, user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	if !user.IsEmailVerified() && s.unverifiedAccess == UnverifiedAccessDeny {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Com 2FA ativo, o primeiro fator apenas libera a segunda etapa do login
+	if user.IsMFAEnabled() {
+		mfaToken, err := s.jwtService.GenerateMFAToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// checkCredentials busca o usuário pelo email e confere a senha
+func (s *AuthService) checkCredentials(ctx context.Context, input model.LoginInput) (*model.User, error) {
 	user, err := s.userRepo.FindByEmail(ctx, input.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -68,13 +189,274 @@ func (s *AuthService) Login(ctx context.Context, input model.LoginInput) (*model
 		return nil, err
 	}
 
-	// Verifica a senha
 	if err := user.ComparePassword(input.Password); err != nil {
 		return nil, ErrInvalidCredentials
 	}
 
-	// Gera os tokens
-	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID)
+	// Com a senha em mãos, atualiza hashes legados (bcrypt) ou com parâmetros
+	// abaixo da política atual. Uma falha aqui não impede o login
+	if user.PasswordNeedsRehash() {
+		if err := s.rehashPassword(ctx, user, input.Password); err != nil {
+			log.Printf("Erro ao atualizar o hash da senha do usuário %s: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// rehashPassword grava um novo hash da senha com o algoritmo e os parâmetros atuais
+func (s *AuthService) rehashPassword(ctx context.Context, user *model.User, password string) error {
+	passwordHash, err := model.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+		return err
+	}
+	user.PasswordHash = passwordHash
+	return nil
+}
+
+// Refresh troca um refresh token válido por um novo par de tokens. Cada refresh
+// token só pode ser usado uma vez; a reutilização revoga toda a família
+func (s *AuthService) Refresh(ctx context.Context, input model.RefreshTokenInput, client model.ClientInfo) (*model.LoginResponse, error) {
+	if _, err := s.jwtService.ValidateToken(input.RefreshToken, auth.TokenTypeRefresh); err != nil {
+		return nil, ErrInvalidRefresh
+	}
+
+	stored, err := s.refreshRepo.FindByHash(ctx, auth.HashToken(input.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidRefresh
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefresh
+	}
+
+	used, err := s.refreshRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		// O token já foi trocado antes: provável vazamento, encerra a sessão inteira
+		if err := s.endSession(ctx, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshReused
+	}
+
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidRefresh
+		}
+		return nil, err
+	}
+
+	response, err := s.issueTokens(ctx, user, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessions.Touch(ctx, stored.FamilyID, client); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Authenticate valida um token de acesso (JWT de sessão ou token pessoal) e
+// verifica se ele não foi revogado nem pertence a uma conta desativada
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*auth.Claims, error) {
+	// Tokens pessoais são opacos e validados no banco
+	if strings.HasPrefix(token, auth.PersonalTokenPrefix) {
+		return s.authenticatePersonalToken(ctx, token)
+	}
+
+	claims, err := s.jwtService.ValidateToken(token, auth.TokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.revocations.IsRevoked(claims) {
+		return nil, ErrTokenRevoked
+	}
+
+	// A lista de revogação só é sincronizada entre as instâncias periodicamente;
+	// a desativação da conta vale imediatamente
+	if _, err := s.tokenOwner(ctx, claims.UserID, ErrTokenRevoked); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// authenticatePersonalToken valida um token pessoal e aplica o papel e a
+// restrição de leitura atuais do dono, como nos tokens de sessão. Assim um token
+// criado antes de a conta ficar somente leitura também passa a ser restrito
+func (s *AuthService) authenticatePersonalToken(ctx context.Context, token string) (*auth.Claims, error) {
+	claims, err := s.personalTokens.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.tokenOwner(ctx, claims.UserID, ErrInvalidPersonalToken)
+	if err != nil {
+		return nil, err
+	}
+
+	claims.Role = user.Role
+	claims.ReadOnly = s.isReadOnly(user)
+	return claims, nil
+}
+
+// tokenOwner carrega o dono de um token, rejeitando contas desativadas. Se o
+// usuário não existir mais, retorna notFound
+func (s *AuthService) tokenOwner(ctx context.Context, userID string, notFound error) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound
+		}
+		return nil, err
+	}
+
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	return user, nil
+}
+
+// Logout revoga o token de acesso atual e encerra a sua sessão. Tokens emitidos
+// antes do registro de sessões não a carregam e dependem do refresh token
+// informado para encerrar a família
+func (s *AuthService) Logout(ctx context.Context, claims *auth.Claims, input model.LogoutInput) error {
+	if err := s.revocations.RevokeToken(ctx, claims); err != nil {
+		return err
+	}
+
+	if claims.SessionID != "" {
+		return s.endSession(ctx, claims.UserID, claims.SessionID)
+	}
+
+	if input.RefreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.refreshRepo.FindByHash(ctx, auth.HashToken(input.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	if stored.UserID != claims.UserID {
+		return nil
+	}
+
+	return s.endSession(ctx, claims.UserID, stored.FamilyID)
+}
+
+// endSession encerra a sessão da família de refresh tokens. Famílias emitidas
+// antes do registro de sessões não têm sessão e apenas são revogadas
+func (s *AuthService) endSession(ctx context.Context, userID, familyID string) error {
+	err := s.sessions.Revoke(ctx, userID, familyID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return s.refreshRepo.RevokeFamily(ctx, familyID)
+	}
+	return err
+}
+
+// LogoutAll revoga todos os tokens emitidos para o usuário até agora, inclusive
+// os tokens de acesso pessoal
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) error {
+	if err := s.revocations.RevokeUser(ctx, userID, time.Now(), s.jwtService.AccessExpiry()); err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.personalTokens.DeleteAll(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeAll(ctx, userID)
+}
+
+// IssueTokens inicia uma nova sessão para o usuário, emitindo um novo par de tokens
+func (s *AuthService) IssueTokens(ctx context.Context, userID string, client model.ClientInfo) (*model.LoginResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, client)
+}
+
+// ListSessions lista as sessões ativas do usuário; currentID é a sessão do token da requisição
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentID string) ([]model.Session, error) {
+	return s.sessions.List(ctx, userID, currentID)
+}
+
+// RevokeSession encerra uma sessão do usuário (logout remoto)
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return s.sessions.Revoke(ctx, userID, sessionID)
+}
+
+// startSession registra uma nova sessão e emite os tokens iniciando a sua
+// família de refresh tokens
+func (s *AuthService) startSession(ctx context.Context, user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	// Entrar na conta dentro do prazo de exclusão cancela o pedido
+	if user.DeletionScheduledAt != nil {
+		if err := s.userRepo.CancelDeletion(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		user.DeletionScheduledAt = nil
+	}
+
+	sessionID, err := s.sessions.Start(ctx, user.ID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, sessionID)
+}
+
+// isReadOnly indica se o acesso do usuário é restrito à leitura: pelo papel ou,
+// enquanto o email não for verificado, pela configuração de acesso
+func (s *AuthService) isReadOnly(user *model.User) bool {
+	return user.Role == model.RoleReadOnly ||
+		(!user.IsEmailVerified() && s.unverifiedAccess == UnverifiedAccessReadOnly)
+}
+
+// issueTokens gera um novo par de tokens e persiste o hash do refresh token.
+// A família de refresh tokens é a própria sessão
+func (s *AuthService) issueTokens(ctx context.Context, user *model.User, familyID string) (*model.LoginResponse, error) {
+	// Todas as formas de obter tokens passam por aqui, inclusive o refresh
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	accessToken, refreshToken, err := s.jwtService.GenerateToken(user.ID, auth.WithRole(user.Role), auth.WithReadOnly(s.isReadOnly(user)), auth.WithSession(familyID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshRepo.Create(ctx, &model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.jwtService.RefreshExpiry()),
+	})
 	if err != nil {
 		return nil, err
 	}
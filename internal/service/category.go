@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCategoryNotFound     = errors.New("categoria não encontrada")
+	ErrCategoryExists       = errors.New("já existe uma categoria com esse nome")
+	ErrCategoryInUse        = errors.New("categoria usada em despesas ou com subcategorias; arquive-a em vez de removê-la")
+	ErrCategoryParent       = errors.New("categoria pai não encontrada")
+	ErrCategoryCycle        = errors.New("a categoria não pode ficar abaixo de si mesma ou de uma subcategoria")
+	ErrCategoryArchived     = errors.New("categoria arquivada")
+	ErrInvalidCategoryName  = errors.New("nome da categoria deve ter entre 1 e 50 caracteres")
+	ErrInvalidCategoryColor = errors.New("cor da categoria deve estar no formato #RRGGBB")
+	ErrInvalidCategoryIcon  = errors.New("ícone da categoria deve ter no máximo 50 caracteres")
+)
+
+var categoryColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// CategoryService gerencia as categorias de despesas de cada usuário
+type CategoryService struct {
+	repo *repository.CategoryRepository
+}
+
+// NewCategoryService cria uma nova instância do serviço de categorias
+func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{repo: repo}
+}
+
+// List lista as categorias do usuário. As arquivadas só são incluídas quando
+// solicitado
+func (s *CategoryService) List(ctx context.Context, userID string, includeArchived bool) ([]*model.UserCategory, error) {
+	return s.repo.List(ctx, userID, includeArchived)
+}
+
+// Create cria uma categoria para o usuário
+func (s *CategoryService) Create(ctx context.Context, userID string, input model.CreateCategoryInput) (*model.UserCategory, error) {
+	name, err := normalizeCategoryName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCategoryStyle(input.Color, input.Icon); err != nil {
+		return nil, err
+	}
+
+	parentID := emptyToNil(input.ParentID)
+	if parentID != nil {
+		if _, err := s.parent(ctx, userID, *parentID); err != nil {
+			return nil, err
+		}
+	}
+
+	category := &model.UserCategory{
+		UserID:   userID,
+		ParentID: parentID,
+		Name:     model.Category(name),
+		Color:    emptyToNil(input.Color),
+		Icon:     emptyToNil(input.Icon),
+	}
+	if err := s.repo.Create(ctx, category); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrCategoryExists
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// Update altera o nome, a categoria pai, a cor, o ícone ou o arquivamento de uma
+// categoria. Renomear uma categoria altera o nome exibido em todas as suas
+// despesas. A categoria não pode ser movida para baixo de si mesma nem de uma
+// das suas subcategorias
+func (s *CategoryService) Update(ctx context.Context, userID, id string, input model.UpdateCategoryInput) (*model.UserCategory, error) {
+	category, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name, err := normalizeCategoryName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		category.Name = model.Category(name)
+	}
+	if err := validateCategoryStyle(input.Color, input.Icon); err != nil {
+		return nil, err
+	}
+	if input.ParentID != nil {
+		parentID := emptyToNil(input.ParentID)
+		if parentID != nil {
+			if err := s.checkParent(ctx, userID, category.ID, *parentID); err != nil {
+				return nil, err
+			}
+		}
+		category.ParentID = parentID
+	}
+	if input.Color != nil {
+		category.Color = emptyToNil(input.Color)
+	}
+	if input.Icon != nil {
+		category.Icon = emptyToNil(input.Icon)
+	}
+	if input.Archived != nil {
+		category.Archived = *input.Archived
+	}
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicate):
+			return nil, ErrCategoryExists
+		case err == sql.ErrNoRows:
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// Delete remove uma categoria que não é usada por nenhuma despesa. Categorias
+// em uso devem ser arquivadas
+func (s *CategoryService) Delete(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrCategoryNotFound
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInUse):
+			return ErrCategoryInUse
+		case err == sql.ErrNoRows:
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Resolve busca pelo nome, sem diferenciar maiúsculas, a categoria do usuário a
+// ser usada em uma despesa. Categorias arquivadas são recusadas
+func (s *CategoryService) Resolve(ctx context.Context, userID string, name model.Category) (*model.UserCategory, error) {
+	category, err := s.repo.FindByName(ctx, userID, strings.TrimSpace(string(name)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	if category.Archived {
+		return nil, ErrCategoryArchived
+	}
+	return category, nil
+}
+
+func (s *CategoryService) get(ctx context.Context, userID, id string) (*model.UserCategory, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	category, err := s.repo.FindByID(ctx, userID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// parent busca a categoria pai informada pelo usuário
+func (s *CategoryService) parent(ctx context.Context, userID, id string) (*model.UserCategory, error) {
+	parent, err := s.get(ctx, userID, id)
+	if errors.Is(err, ErrCategoryNotFound) {
+		return nil, ErrCategoryParent
+	}
+	return parent, err
+}
+
+// checkParent confere se a categoria id pode ficar abaixo de parentID, subindo
+// a partir da nova categoria pai: encontrar a própria categoria no caminho
+// indica que parentID é ela mesma ou uma das suas subcategorias
+func (s *CategoryService) checkParent(ctx context.Context, userID, id, parentID string) error {
+	if _, err := s.parent(ctx, userID, parentID); err != nil {
+		return err
+	}
+
+	categories, err := s.repo.List(ctx, userID, true)
+	if err != nil {
+		return err
+	}
+	parents := make(map[string]*string, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	// O limite de passos protege contra ciclos já gravados no banco
+	current := &parentID
+	for steps := 0; current != nil && steps <= len(categories); steps++ {
+		if *current == id {
+			return ErrCategoryCycle
+		}
+		current = parents[*current]
+	}
+	return nil
+}
+
+func normalizeCategoryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 50 {
+		return "", ErrInvalidCategoryName
+	}
+	return name, nil
+}
+
+// validateCategoryStyle confere a cor e o ícone informados. Valores vazios são
+// aceitos e removem a cor ou o ícone
+func validateCategoryStyle(color, icon *string) error {
+	if color != nil && *color != "" && !categoryColorPattern.MatchString(*color) {
+		return ErrInvalidCategoryColor
+	}
+	if icon != nil && utf8.RuneCountInString(*icon) > 50 {
+		return ErrInvalidCategoryIcon
+	}
+	return nil
+}
+
+func emptyToNil(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
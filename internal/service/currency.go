@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"expenseapi/internal/currency"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("moeda sem cotação cadastrada")
+	ErrNoRates             = errors.New("nenhuma cotação informada")
+)
+
+// CurrencyService gerencia as cotações e a moeda base dos usuários. As cotações
+// ficam no banco e são mantidas em memória para converter as despesas
+type CurrencyService struct {
+	rates    *currency.Table
+	rateRepo *repository.ExchangeRateRepository
+	userRepo *repository.UserRepository
+}
+
+// NewCurrencyService cria uma nova instância do serviço de moedas
+func NewCurrencyService(rates *currency.Table, rateRepo *repository.ExchangeRateRepository, userRepo *repository.UserRepository) *CurrencyService {
+	return &CurrencyService{rates: rates, rateRepo: rateRepo, userRepo: userRepo}
+}
+
+// Load carrega na memória as cotações gravadas no banco
+func (s *CurrencyService) Load(ctx context.Context) error {
+	rates, err := s.rateRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	return s.rates.Add(rates...)
+}
+
+// Start recarrega periodicamente as cotações do banco até o contexto ser
+// cancelado, incorporando as importadas por outras instâncias
+func (s *CurrencyService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(ctx); err != nil {
+				log.Printf("Erro ao recarregar as cotações: %v", err)
+			}
+		}
+	}
+}
+
+// Import grava as cotações e as disponibiliza imediatamente para conversão
+// nesta instância; as demais as recebem na próxima recarga
+func (s *CurrencyService) Import(ctx context.Context, rates []model.ExchangeRate) (int, error) {
+	if len(rates) == 0 {
+		return 0, ErrNoRates
+	}
+	if err := s.rateRepo.Upsert(ctx, rates); err != nil {
+		return 0, err
+	}
+	if err := s.rates.Add(rates...); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// ImportFile importa um arquivo de cotações em CSV ou no XML do Banco Central
+// Europeu, conforme a extensão
+func (s *CurrencyService) ImportFile(ctx context.Context, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var rates []model.ExchangeRate
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rates, err = currency.ParseCSV(file)
+	case ".xml":
+		rates, err = currency.ParseECB(file)
+	default:
+		return 0, fmt.Errorf("formato de arquivo de cotações não suportado: %s", path)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return s.Import(ctx, rates)
+}
+
+// Supported indica se a moeda pode ser convertida
+func (s *CurrencyService) Supported(code string) bool {
+	return s.rates.Has(code)
+}
+
+// Convert converte o valor com as cotações da data
+func (s *CurrencyService) Convert(amount model.Money, from, to string, date time.Time) (model.Money, error) {
+	return s.rates.Convert(amount, from, to, date)
+}
+
+// BaseCurrency retorna a moeda base do usuário
+func (s *CurrencyService) BaseCurrency(ctx context.Context, userID string) (string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	return user.BaseCurrency, nil
+}
+
+// SetBaseCurrency altera a moeda base do usuário. Apenas moedas com cotação
+// podem ser escolhidas, além da moeda padrão
+func (s *CurrencyService) SetBaseCurrency(ctx context.Context, userID, code string) (*model.User, error) {
+	code, err := currency.NormalizeCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if code != currency.Default && !s.rates.Has(code) {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	if err := s.userRepo.UpdateBaseCurrency(ctx, userID, code); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -2,34 +2,117 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"expenseapi/internal/currency"
 	"expenseapi/internal/model"
 	"expenseapi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAmountNotPositive = errors.New("valor deve ser maior que zero")
+	ErrInvalidSort       = errors.New("ordenação inválida: use sort=date, amount, created_at ou description e order=asc ou desc")
+	ErrInvalidLimit      = errors.New("limit deve estar entre 1 e 200")
+	ErrInvalidCursor     = errors.New("cursor inválido para esta ordenação")
+	ErrMerchantTooLong   = errors.New("estabelecimento deve ter no máximo 255 caracteres")
+	ErrNotesTooLong      = errors.New("observações devem ter no máximo 2000 caracteres")
+	ErrEmptySearch       = errors.New("informe o texto da busca em q")
+)
+
+// Tamanho padrão e máximo das páginas da listagem de despesas
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// Tamanho máximo do estabelecimento e das observações, em caracteres
+const (
+	maxMerchantLength = 255
+	maxNotesLength    = 2000
 )
 
+// CurrencyConverter fornece a moeda base do usuário e converte valores entre
+// moedas. É implementado por CurrencyService
+type CurrencyConverter interface {
+	BaseCurrency(ctx context.Context, userID string) (string, error)
+	Supported(code string) bool
+	Convert(amount model.Money, from, to string, date time.Time) (model.Money, error)
+}
+
+// CategoryResolver encontra a categoria do usuário a ser usada em uma despesa e
+// lista a árvore de categorias para os totais. É implementado por CategoryService
+type CategoryResolver interface {
+	Resolve(ctx context.Context, userID string, name model.Category) (*model.UserCategory, error)
+	List(ctx context.Context, userID string, includeArchived bool) ([]*model.UserCategory, error)
+}
+
 // ExpenseService gerencia a lógica de negócios relacionada a despesas
 type ExpenseService struct {
-	repo repository.ExpenseRepository
+	repo       repository.ExpenseRepository
+	categories CategoryResolver
+	currencies CurrencyConverter
 }
 
 // NewExpenseService cria uma nova instância do serviço de despesas
-func NewExpenseService(repo repository.ExpenseRepository) *ExpenseService {
-	return &ExpenseService{repo: repo}
+func NewExpenseService(repo repository.ExpenseRepository, categories CategoryResolver, currencies CurrencyConverter) *ExpenseService {
+	return &ExpenseService{repo: repo, categories: categories, currencies: currencies}
 }
 
 // Create cria uma nova despesa
 func (s *ExpenseService) Create(ctx context.Context, userID string, input *model.CreateExpenseInput) (*model.Expense, error) {
+	if !input.Amount.IsPositive() {
+		return nil, ErrAmountNotPositive
+	}
+
 	date, err := time.Parse("2006-01-02", input.Date)
 	if err != nil {
 		return nil, err
 	}
 
+	category, err := s.categories.Resolve(ctx, userID, input.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, notes := strings.TrimSpace(input.Merchant), strings.TrimSpace(input.Notes)
+	if err := validateExpenseText(merchant, notes); err != nil {
+		return nil, err
+	}
+
+	base, err := s.currencies.BaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	code := base
+	if input.Currency != "" {
+		if code, err = s.validateCurrency(input.Currency, base); err != nil {
+			return nil, err
+		}
+	}
+
 	expense := &model.Expense{
 		UserID:      userID,
 		Amount:      input.Amount,
+		Currency:    code,
 		Description: input.Description,
-		Category:    input.Category,
+		Merchant:    merchant,
+		Notes:       notes,
+		Category:    category.Name,
+		CategoryID:  category.ID,
+		Tags:        tags,
 		Date:        date,
 	}
 
@@ -38,34 +121,281 @@ func (s *ExpenseService) Create(ctx context.Context, userID string, input *model
 		return nil, err
 	}
 
+	if err := s.convert(base, expense); err != nil {
+		return nil, err
+	}
 	return expense, nil
 }
 
+// validateExpenseText confere o tamanho do estabelecimento e das observações
+func validateExpenseText(merchant, notes string) error {
+	if utf8.RuneCountInString(merchant) > maxMerchantLength {
+		return ErrMerchantTooLong
+	}
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return ErrNotesTooLong
+	}
+	return nil
+}
+
+// validateCurrency normaliza o código da moeda e confere se ela pode ser
+// convertida para a moeda base
+func (s *ExpenseService) validateCurrency(code, base string) (string, error) {
+	code, err := currency.NormalizeCode(code)
+	if err != nil {
+		return "", err
+	}
+	if code != base && !s.currencies.Supported(code) {
+		return "", ErrUnsupportedCurrency
+	}
+	return code, nil
+}
+
+// withBaseAmounts preenche o valor das despesas na moeda base do usuário
+func (s *ExpenseService) withBaseAmounts(ctx context.Context, userID string, expenses ...*model.Expense) error {
+	base, err := s.currencies.BaseCurrency(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.convert(base, expenses...)
+}
+
+// convert converte as despesas para a moeda base com a cotação da data de cada
+// uma. Despesas sem cotação para a data ficam sem base_amount
+func (s *ExpenseService) convert(base string, expenses ...*model.Expense) error {
+	for _, expense := range expenses {
+		expense.BaseCurrency = base
+		converted, err := s.currencies.Convert(expense.Amount, expense.Currency, base, expense.Date)
+		if errors.Is(err, currency.ErrRateNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		expense.BaseAmount = &converted
+	}
+	return nil
+}
+
 // GetByID busca uma despesa pelo ID
 func (s *ExpenseService) GetByID(ctx context.Context, id string, userID string) (*model.Expense, error) {
-	return s.repo.GetByID(ctx, id, userID)
+	expense, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withBaseAmounts(ctx, userID, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
 }
 
 // List retorna todas as despesas de um usuário com filtros opcionais
 func (s *ExpenseService) List(ctx context.Context, userID string, filter *model.ExpenseFilter) ([]*model.Expense, error) {
-	return s.repo.List(ctx, userID, filter)
+	expenses, err := s.repo.List(ctx, userID, normalizeFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withBaseAmounts(ctx, userID, expenses...); err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// ListPage retorna uma página das despesas do usuário. Por padrão, as despesas
+// vêm das mais recentes para as mais antigas, em páginas de 50. O cursor da
+// próxima página só vale com a mesma ordenação
+func (s *ExpenseService) ListPage(ctx context.Context, userID string, filter *model.ExpenseFilter, params model.ExpenseListParams) (*model.ExpensePage, error) {
+	page := model.ExpensePageQuery{Sort: params.Sort, Limit: params.Limit}
+	if page.Sort == "" {
+		page.Sort = model.ExpenseSortDate
+	}
+	switch page.Sort {
+	case model.ExpenseSortDate, model.ExpenseSortAmount, model.ExpenseSortCreatedAt, model.ExpenseSortDescription:
+	default:
+		return nil, ErrInvalidSort
+	}
+
+	switch params.Order {
+	case "", "desc":
+		page.Desc = true
+	case "asc":
+	default:
+		return nil, ErrInvalidSort
+	}
+
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	if page.Limit < 0 || page.Limit > maxPageSize {
+		return nil, ErrInvalidLimit
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeCursor(params.Cursor)
+		if err != nil || cursor.Sort != page.Sort || cursor.Desc != page.Desc {
+			return nil, ErrInvalidCursor
+		}
+		page.After = cursor
+	}
+
+	// Uma despesa a mais indica que existe uma próxima página
+	limit := page.Limit
+	page.Limit++
+	expenses, err := s.repo.ListPage(ctx, userID, normalizeFilter(filter), page)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ExpensePage{Items: expenses}
+	if len(expenses) > limit {
+		result.Items = expenses[:limit]
+		next := encodeCursor(cursorFor(result.Items[limit-1], page.Sort, page.Desc))
+		result.NextCursor = &next
+	}
+
+	if err := s.withBaseAmounts(ctx, userID, result.Items...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cursorFor monta o cursor que aponta para a despesa na ordenação informada
+func cursorFor(expense *model.Expense, sort string, desc bool) model.ExpenseCursor {
+	cursor := model.ExpenseCursor{Sort: sort, Desc: desc, ID: expense.ID}
+	switch sort {
+	case model.ExpenseSortDate:
+		cursor.Value = expense.Date.Format("2006-01-02")
+	case model.ExpenseSortAmount:
+		cursor.Value = expense.Amount.String()
+	case model.ExpenseSortCreatedAt:
+		cursor.Value = expense.CreatedAt.Format(time.RFC3339Nano)
+	case model.ExpenseSortDescription:
+		cursor.Value = expense.Description
+	}
+	return cursor
+}
+
+// encodeCursor serializa o cursor em um texto opaco para o cliente
+func encodeCursor(cursor model.ExpenseCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*model.ExpenseCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor model.ExpenseCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, err
+	}
+	if err := validateCursorValue(cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// validateCursorValue confere se o valor do cursor tem o formato do campo de
+// ordenação, para que um cursor adulterado não chegue ao banco
+func validateCursorValue(cursor model.ExpenseCursor) error {
+	var err error
+	switch cursor.Sort {
+	case model.ExpenseSortDate:
+		_, err = time.Parse("2006-01-02", cursor.Value)
+	case model.ExpenseSortAmount:
+		_, err = model.ParseMoney(cursor.Value)
+	case model.ExpenseSortCreatedAt:
+		_, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	}
+	return err
+}
+
+// Search busca as despesas do usuário pelo texto na descrição, no
+// estabelecimento e nas observações, sem diferenciar acentos e considerando
+// variações das palavras em português. Retorna até limit despesas (50 por
+// padrão), da mais relevante para a menos relevante, que também podem ser
+// restringidas pelo filtro
+func (s *ExpenseService) Search(ctx context.Context, userID string, text string, filter *model.ExpenseFilter, limit int) (*model.ExpenseSearchResults, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptySearch
+	}
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit < 1 || limit > maxPageSize {
+		return nil, ErrInvalidLimit
+	}
+
+	results, err := s.repo.Search(ctx, userID, text, normalizeFilter(filter), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]*model.Expense, len(results))
+	for i, result := range results {
+		expenses[i] = &result.Expense
+	}
+	if err := s.withBaseAmounts(ctx, userID, expenses...); err != nil {
+		return nil, err
+	}
+	return &model.ExpenseSearchResults{Items: results}, nil
 }
 
 // Update atualiza uma despesa existente
 func (s *ExpenseService) Update(ctx context.Context, id string, userID string, input *model.UpdateExpenseInput) (*model.Expense, error) {
+	if input.Amount != nil && !input.Amount.IsPositive() {
+		return nil, ErrAmountNotPositive
+	}
+
 	expense, err := s.repo.GetByID(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	base, err := s.currencies.BaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Currency != nil {
+		if expense.Currency, err = s.validateCurrency(*input.Currency, base); err != nil {
+			return nil, err
+		}
+	}
 	if input.Amount != nil {
 		expense.Amount = *input.Amount
 	}
 	if input.Description != nil {
 		expense.Description = *input.Description
 	}
+	if input.Merchant != nil {
+		expense.Merchant = strings.TrimSpace(*input.Merchant)
+	}
+	if input.Notes != nil {
+		expense.Notes = strings.TrimSpace(*input.Notes)
+	}
+	if err := validateExpenseText(expense.Merchant, expense.Notes); err != nil {
+		return nil, err
+	}
 	if input.Category != nil {
-		expense.Category = *input.Category
+		category, err := s.categories.Resolve(ctx, userID, *input.Category)
+		if err != nil {
+			return nil, err
+		}
+		expense.Category = category.Name
+		expense.CategoryID = category.ID
+	}
+	if input.Tags != nil {
+		if expense.Tags, err = normalizeTags(*input.Tags); err != nil {
+			return nil, err
+		}
 	}
 	if input.Date != nil {
 		date, err := time.Parse("2006-01-02", *input.Date)
@@ -80,6 +410,9 @@ func (s *ExpenseService) Update(ctx context.Context, id string, userID string, i
 		return nil, err
 	}
 
+	if err := s.convert(base, expense); err != nil {
+		return nil, err
+	}
 	return expense, nil
 }
 
@@ -88,8 +421,20 @@ func (s *ExpenseService) Delete(ctx context.Context, id string, userID string) e
 	return s.repo.Delete(ctx, id, userID)
 }
 
-// GetExpensesByPeriod retorna despesas filtradas por período
-func (s *ExpenseService) GetExpensesByPeriod(ctx context.Context, userID string, period string) ([]*model.Expense, error) {
+// normalizeFilter retorna uma cópia do filtro com as tags normalizadas como nas
+// despesas. Tags vazias são ignoradas
+func normalizeFilter(filter *model.ExpenseFilter) *model.ExpenseFilter {
+	if filter == nil || len(filter.Tags) == 0 {
+		return filter
+	}
+	normalized := *filter
+	normalized.Tags = cleanTags(filter.Tags)
+	return &normalized
+}
+
+// PeriodFilter retorna o filtro de um período predefinido até hoje: week,
+// month ou quarter. Retorna nil para períodos desconhecidos
+func PeriodFilter(period string) *model.ExpenseFilter {
 	now := time.Now()
 	var startDate, endDate time.Time
 
@@ -101,15 +446,163 @@ func (s *ExpenseService) GetExpensesByPeriod(ctx context.Context, userID string,
 	case "quarter":
 		startDate = now.AddDate(0, -3, 0)
 	default:
-		return nil, nil
+		return nil
 	}
 
 	endDate = now
 
-	filter := &model.ExpenseFilter{
+	return &model.ExpenseFilter{
 		StartDate: &startDate,
 		EndDate:   &endDate,
 	}
+}
+
+// Summary soma as despesas do usuário na moeda base, no total, por categoria e
+// por moeda de origem. O banco soma cada grupo de categoria, moeda e data em
+// NUMERIC; cada grupo é convertido com a cotação da sua data e os totais são
+// somados em centavos, sem arredondamentos além da conversão. O total de cada
+// categoria inclui o das suas subcategorias, e as categorias são listadas na
+// ordem da árvore, cada pai antes dos filhos
+func (s *ExpenseService) Summary(ctx context.Context, userID string, filter *model.ExpenseFilter) (*model.ExpenseSummary, error) {
+	base, err := s.currencies.BaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.repo.SummarizeGroups(ctx, userID, normalizeFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.categories.List(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*model.UserCategory, len(tree))
+	for _, category := range tree {
+		nodes[category.ID] = category
+	}
+
+	summary := &model.ExpenseSummary{
+		BaseCurrency: base,
+		Currencies:   []model.CurrencyTotal{},
+		Categories:   []model.CategoryTotal{},
+	}
+	categories := make(map[string]*model.CategoryTotal)
+	currencies := make(map[string]int)
+	missing := make(map[string]bool)
+
+	for _, group := range groups {
+		totals := rollUp(categories, nodes, group)
+		mi, ok := currencies[group.Currency]
+		if !ok {
+			mi = len(summary.Currencies)
+			currencies[group.Currency] = mi
+			summary.Currencies = append(summary.Currencies, model.CurrencyTotal{Currency: group.Currency})
+		}
+
+		summary.Count += group.Count
+		for _, total := range totals {
+			total.Count += group.Count
+		}
+		summary.Currencies[mi].Count += group.Count
+		summary.Currencies[mi].Amount = summary.Currencies[mi].Amount.Add(group.Total)
+
+		converted, err := s.currencies.Convert(group.Total, group.Currency, base, group.Date)
+		if errors.Is(err, currency.ErrRateNotFound) {
+			summary.Incomplete = true
+			missing[group.Currency] = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Total = summary.Total.Add(converted)
+		for _, total := range totals {
+			total.Total = total.Total.Add(converted)
+		}
+		baseAmount := converted
+		if current := summary.Currencies[mi].BaseAmount; current != nil {
+			baseAmount = current.Add(converted)
+		}
+		summary.Currencies[mi].BaseAmount = &baseAmount
+	}
+
+	for i := range summary.Currencies {
+		if missing[summary.Currencies[i].Currency] {
+			summary.Currencies[i].BaseAmount = nil
+		}
+	}
+
+	summary.Categories = sortCategoryTotals(tree, categories)
+	return summary, nil
+}
+
+// rollUp retorna os totais da categoria do grupo e de todas as categorias acima
+// dela, criando os que ainda não existem
+func rollUp(totals map[string]*model.CategoryTotal, nodes map[string]*model.UserCategory, group model.ExpenseGroupTotal) []*model.CategoryTotal {
+	var chain []*model.CategoryTotal
+	seen := make(map[string]bool)
+	// seen protege contra ciclos já gravados no banco
+	for id := &group.CategoryID; id != nil && !seen[*id]; {
+		seen[*id] = true
+		total, ok := totals[*id]
+		if !ok {
+			total = &model.CategoryTotal{CategoryID: *id, Category: group.Category}
+			if node := nodes[*id]; node != nil {
+				total.ParentID = node.ParentID
+				total.Category = node.Name
+			}
+			totals[*id] = total
+		}
+		chain = append(chain, total)
 
-	return s.repo.List(ctx, userID, filter)
+		node := nodes[*id]
+		if node == nil {
+			break
+		}
+		id = node.ParentID
+	}
+	return chain
+}
+
+// sortCategoryTotals ordena os totais na ordem da árvore de categorias, com cada
+// pai antes dos filhos e os irmãos na ordem da lista. Totais de categorias fora
+// da árvore ficam no fim
+func sortCategoryTotals(tree []*model.UserCategory, totals map[string]*model.CategoryTotal) []model.CategoryTotal {
+	children := make(map[string][]*model.UserCategory)
+	var roots []*model.UserCategory
+	for _, category := range tree {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	sorted := make([]model.CategoryTotal, 0, len(totals))
+	visited := make(map[string]bool, len(totals))
+	var visit func(categories []*model.UserCategory)
+	visit = func(categories []*model.UserCategory) {
+		for _, category := range categories {
+			total, ok := totals[category.ID]
+			if !ok || visited[category.ID] {
+				continue
+			}
+			visited[category.ID] = true
+			sorted = append(sorted, *total)
+			visit(children[category.ID])
+		}
+	}
+	visit(roots)
+
+	var rest []model.CategoryTotal
+	for id, total := range totals {
+		if !visited[id] {
+			rest = append(rest, *total)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Category < rest[j].Category })
+	return append(sorted, rest...)
 }
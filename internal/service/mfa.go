@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+// recoveryCodeCount é a quantidade de códigos de recuperação gerados na ativação
+const recoveryCodeCount = 10
+
+// maxMFAFailures é a quantidade de códigos errados aceitos para um mesmo token
+// intermediário; depois dele o token é invalidado e o login recomeça pela senha
+const maxMFAFailures = 5
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("autenticação em dois fatores já está ativa")
+	ErrMFANotPending     = errors.New("nenhuma configuração de dois fatores pendente")
+	ErrInvalidMFACode    = errors.New("código de verificação inválido")
+	ErrInvalidMFAToken   = errors.New("token de verificação inválido ou expirado")
+)
+
+// MFAService gerencia a autenticação em dois fatores (TOTP)
+type MFAService struct {
+	userRepo    *repository.UserRepository
+	mfaRepo     *repository.MFARepository
+	jwtService  *auth.JWTService
+	authService *AuthService
+	limiter     *auth.LoginLimiter
+	issuer      string
+}
+
+// NewMFAService cria uma nova instância do serviço de 2FA
+func NewMFAService(userRepo *repository.UserRepository, mfaRepo *repository.MFARepository, jwtService *auth.JWTService, authService *AuthService, limiter *auth.LoginLimiter, issuer string) *MFAService {
+	return &MFAService{
+		userRepo:    userRepo,
+		mfaRepo:     mfaRepo,
+		jwtService:  jwtService,
+		authService: authService,
+		limiter:     limiter,
+		issuer:      issuer,
+	}
+}
+
+// Setup gera um novo segredo TOTP pendente de confirmação
+func (s *MFAService) Setup(ctx context.Context, userID string) (*model.MFASetupResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsMFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.SetPendingSecret(ctx, user.ID, secret); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &model.MFASetupResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm ativa o 2FA após conferir um código do autenticador e retorna
+// os códigos de recuperação, que não podem ser consultados novamente
+func (s *MFAService) Confirm(ctx context.Context, userID string, input model.MFAConfirmInput) (*model.MFAConfirmResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsMFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrMFANotPending
+	}
+
+	step, ok := auth.ValidateTOTP(*user.TOTPSecret, input.Code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = auth.HashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.mfaRepo.Enable(ctx, user.ID, step, hashes); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMFANotPending
+		}
+		return nil, err
+	}
+
+	return &model.MFAConfirmResponse{RecoveryCodes: codes}, nil
+}
+
+// Verify conclui o login com 2FA, trocando o token intermediário e um código
+// do autenticador (ou de recuperação) pelo par de tokens definitivo. As falhas
+// contam para o bloqueio da conta e do IP, como as senhas erradas no login
+func (s *MFAService) Verify(ctx context.Context, input model.MFAVerifyInput, client model.ClientInfo) (*model.LoginResponse, error) {
+	claims, err := s.jwtService.ValidateToken(input.MFAToken, auth.TokenTypeMFA)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.findUser(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if !user.IsMFAEnabled() {
+		return nil, ErrInvalidMFAToken
+	}
+
+	// Mesma chave do login, para que as duas etapas somem falhas na mesma conta
+	email := normalizeEmail(user.Email)
+	event := model.LoginEvent{Email: email, IP: client.IP, UserAgent: client.UserAgent}
+	retryAfter, err := s.limiter.Check(ctx, email, client.IP)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		event.Result = model.LoginEventBlocked
+		s.limiter.Record(ctx, event)
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	failures, err := s.limiter.TokenFailures(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if failures >= maxMFAFailures {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if err := s.checkCode(ctx, user, input); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			event.Result = model.LoginEventFailure
+			s.limiter.Record(ctx, event)
+			if err := s.limiter.Failure(ctx, email, client.IP); err != nil {
+				return nil, err
+			}
+			if err := s.limiter.TokenFailure(ctx, claims.ID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	event.Result = model.LoginEventSuccess
+	s.limiter.Record(ctx, event)
+	if err := s.limiter.Success(ctx, email); err != nil {
+		return nil, err
+	}
+
+	return s.authService.IssueTokens(ctx, user.ID, client)
+}
+
+// checkCode confere o código do autenticador ou de recuperação informado
+func (s *MFAService) checkCode(ctx context.Context, user *model.User, input model.MFAVerifyInput) error {
+	switch {
+	case input.Code != "":
+		step, ok := auth.ValidateTOTP(*user.TOTPSecret, input.Code, time.Now())
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		// Cada código só pode ser usado uma vez
+		claimed, err := s.mfaRepo.ClaimStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidMFACode
+		}
+	case input.RecoveryCode != "":
+		used, err := s.mfaRepo.UseRecoveryCode(ctx, user.ID, auth.HashToken(normalizeRecoveryCode(input.RecoveryCode)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+	default:
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAService) findUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// generateRecoveryCode gera um código no formato xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(secret[:10])
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode ignora hífens, espaços e maiúsculas ao comparar códigos
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
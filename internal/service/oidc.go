@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
+	"expenseapi/internal/oidc"
+	"expenseapi/internal/repository"
+)
+
+var (
+	ErrUnknownProvider      = errors.New("provedor OIDC desconhecido")
+	ErrInvalidOIDCState     = errors.New("login OIDC inválido ou expirado")
+	ErrOIDCEmailNotVerified = errors.New("o provedor não confirmou o email")
+	ErrOIDCAccountConflict  = errors.New("já existe uma conta com este email; verifique o email antes de usar o login único")
+)
+
+// oidcStateTTL é o tempo máximo entre o início do login e o callback
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCService gerencia o login por provedores OpenID Connect
+type OIDCService struct {
+	providers   map[string]*oidc.Provider
+	repo        *repository.OIDCRepository
+	userRepo    *repository.UserRepository
+	authService *AuthService
+}
+
+// NewOIDCService cria uma nova instância do serviço de login OIDC
+func NewOIDCService(providers []*oidc.Provider, repo *repository.OIDCRepository, userRepo *repository.UserRepository, authService *AuthService) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &OIDCService{
+		providers:   byName,
+		repo:        repo,
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+// Start inicia o login, retornando a URL do provedor e o state que deve ser
+// vinculado ao navegador do usuário
+func (s *OIDCService) Start(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.repo.CreateState(ctx, &model.OIDCState{
+		StateHash:    auth.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// Callback conclui o login: troca o código, valida o id_token e emite os mesmos
+// tokens do login com senha para o usuário vinculado ou criado
+func (s *OIDCService) Callback(ctx context.Context, providerName, state, code string, client model.ClientInfo) (*model.LoginResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	pending, err := s.repo.ConsumeState(ctx, auth.HashToken(state), providerName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if time.Now().After(pending.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	identity, err := provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, providerName, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authService.CompleteLogin(ctx, user, client)
+}
+
+// resolveUser encontra o usuário da identidade, vinculando-o a uma conta
+// existente com o mesmo email ou criando uma nova
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, identity *oidc.Identity) (*model.User, error) {
+	linked, err := s.repo.FindIdentity(ctx, providerName, identity.Subject)
+	if err == nil {
+		return s.userRepo.FindByID(ctx, linked.UserID)
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Sem vínculo prévio, só o email confirmado pelo provedor identifica a pessoa
+	if !identity.EmailVerified || identity.Email == "" {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		// Uma conta local com email não verificado pode ter sido criada por outra
+		// pessoa; vinculá-la entregaria a conta a quem a criou
+		if !user.IsEmailVerified() {
+			return nil, ErrOIDCAccountConflict
+		}
+	case err == sql.ErrNoRows:
+		user, err = s.provision(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	err = s.repo.CreateIdentity(ctx, &model.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// provision cria a conta de um usuário que entra pela primeira vez pelo
+// provedor. A senha é aleatória; o usuário pode defini-la pela redefinição de senha
+func (s *OIDCService) provision(ctx context.Context, identity *oidc.Identity) (*model.User, error) {
+	password, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := model.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := s.userRepo.Create(ctx, normalizeEmail(identity.Email), passwordHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.MarkEmailVerified(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.FindByID(ctx, userID)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/password"
+)
+
+var ErrWeakPassword = errors.New("senha não atende à política de senhas")
+
+// Classes de caracteres que podem ser exigidas pela política
+const (
+	PasswordClassLower  = "lower"
+	PasswordClassUpper  = "upper"
+	PasswordClassDigit  = "digit"
+	PasswordClassSymbol = "symbol"
+)
+
+// Regras da política, usadas no campo rule das violações
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleEmail     = "not_email"
+	PasswordRuleBreached  = "not_breached"
+)
+
+// MaxPasswordBytes é o limite do bcrypt, mantido para que as senhas continuem
+// válidas caso o algoritmo volte a ser usado
+const MaxPasswordBytes = 72
+
+var passwordClassMessages = map[string]string{
+	PasswordClassLower:  "senha deve conter uma letra minúscula",
+	PasswordClassUpper:  "senha deve conter uma letra maiúscula",
+	PasswordClassDigit:  "senha deve conter um número",
+	PasswordClassSymbol: "senha deve conter um símbolo",
+}
+
+// PasswordPolicyError lista as regras da política que a senha não atende
+type PasswordPolicyError struct {
+	Violations []model.PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Violations[0].Message
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// PasswordPolicy define as regras aplicadas às novas senhas
+type PasswordPolicy struct {
+	minLength int
+	classes   []string
+	breached  *password.BreachedList
+}
+
+// NewPasswordPolicy cria uma política com o tamanho mínimo e as classes de
+// caracteres exigidas. Com breached informado, senhas vazadas são recusadas
+func NewPasswordPolicy(minLength int, classes []string, breached *password.BreachedList) (*PasswordPolicy, error) {
+	for _, class := range classes {
+		if _, ok := passwordClassMessages[class]; !ok {
+			return nil, fmt.Errorf("classe de caracteres desconhecida: %s", class)
+		}
+	}
+	return &PasswordPolicy{minLength: minLength, classes: classes, breached: breached}, nil
+}
+
+// Validate confere a senha contra todas as regras, retornando um
+// *PasswordPolicyError com cada regra não atendida
+func (p *PasswordPolicy) Validate(plain, email string) error {
+	var violations []model.PasswordViolation
+	violate := func(rule, message string) {
+		violations = append(violations, model.PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if utf8.RuneCountInString(plain) < p.minLength {
+		violate(PasswordRuleMinLength, fmt.Sprintf("senha deve ter no mínimo %d caracteres", p.minLength))
+	}
+	if len(plain) > MaxPasswordBytes {
+		violate(PasswordRuleMaxLength, fmt.Sprintf("senha deve ter no máximo %d bytes", MaxPasswordBytes))
+	}
+
+	for _, class := range p.classes {
+		if !containsClass(plain, class) {
+			violate(class, passwordClassMessages[class])
+		}
+	}
+
+	if email != "" && strings.EqualFold(strings.TrimSpace(plain), strings.TrimSpace(email)) {
+		violate(PasswordRuleEmail, "senha não pode ser igual ao email")
+	}
+
+	if p.breached != nil && p.breached.Contains(plain) {
+		violate(PasswordRuleBreached, "senha aparece em vazamentos de dados conhecidos")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func containsClass(plain, class string) bool {
+	for _, r := range plain {
+		switch class {
+		case PasswordClassLower:
+			if unicode.IsLower(r) {
+				return true
+			}
+		case PasswordClassUpper:
+			if unicode.IsUpper(r) {
+				return true
+			}
+		case PasswordClassDigit:
+			if unicode.IsDigit(r) {
+				return true
+			}
+		case PasswordClassSymbol:
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/mailer"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("token de redefinição inválido ou expirado")
+)
+
+// PasswordResetService gerencia o fluxo de redefinição de senha
+type PasswordResetService struct {
+	userRepo    *repository.UserRepository
+	resetRepo   *repository.PasswordResetRepository
+	authService *AuthService
+	mailer      mailer.Mailer
+	appURL      string
+	tokenTTL    time.Duration
+}
+
+// NewPasswordResetService cria uma nova instância do serviço de redefinição de senha
+func NewPasswordResetService(userRepo *repository.UserRepository, resetRepo *repository.PasswordResetRepository, authService *AuthService, mailer mailer.Mailer, appURL string, tokenTTL time.Duration) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		authService: authService,
+		mailer:      mailer,
+		appURL:      appURL,
+		tokenTTL:    tokenTTL,
+	}
+}
+
+// Forgot gera um token de redefinição e o envia por email. Emails não
+// cadastrados e falhas no envio são ignorados silenciosamente para não revelar
+// quais emails existem
+func (s *PasswordResetService) Forgot(ctx context.Context, input model.ForgotPasswordInput) error {
+	user, err := s.userRepo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.resetRepo.Create(ctx, &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appURL, url.QueryEscape(token))
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha",
+		Body: fmt.Sprintf(
+			"Recebemos uma solicitação para redefinir a sua senha.\n\n"+
+				"Use o link abaixo em até %s:\n%s\n\n"+
+				"Se você não fez essa solicitação, ignore este email.",
+			s.tokenTTL, link,
+		),
+	})
+	if err != nil {
+		// Uma falha no envio não pode mudar a resposta, ou ela revelaria que o
+		// email está cadastrado
+		log.Printf("Erro ao enviar email de redefinição de senha para %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+// Reset redefine a senha usando um token válido e encerra todas as sessões do usuário
+func (s *PasswordResetService) Reset(ctx context.Context, input model.ResetPasswordInput) error {
+	stored, err := s.resetRepo.FindByHash(ctx, auth.HashToken(input.Token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	// A política é conferida antes de consumir o token, para que uma senha
+	// recusada não obrigue o usuário a pedir um novo link
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := s.authService.ValidatePassword(input.Password, user.Email); err != nil {
+		return err
+	}
+
+	used, err := s.resetRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidResetToken
+	}
+
+	passwordHash, err := model.HashPassword(input.Password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, stored.UserID, passwordHash); err != nil {
+		return err
+	}
+
+	// Outros tokens pendentes deixam de valer após a troca de senha
+	if err := s.resetRepo.InvalidateForUser(ctx, stored.UserID); err != nil {
+		return err
+	}
+
+	return s.authService.LogoutAll(ctx, stored.UserID)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPersonalTokenNotFound = errors.New("token pessoal não encontrado")
+	ErrInvalidPersonalToken  = errors.New("token pessoal inválido")
+	ErrInvalidTokenName      = errors.New("nome do token deve ter entre 1 e 100 caracteres")
+	ErrInvalidTokenScope     = errors.New("escopo de token inválido")
+	ErrInvalidTokenExpiry    = errors.New("data de expiração deve estar no futuro")
+	ErrWriteScopeNotAllowed  = errors.New("contas somente leitura não podem criar tokens com escopo de escrita")
+)
+
+// PersonalTokenService gerencia os tokens de acesso pessoal
+type PersonalTokenService struct {
+	repo *repository.PersonalTokenRepository
+}
+
+// NewPersonalTokenService cria uma nova instância do serviço de tokens pessoais
+func NewPersonalTokenService(repo *repository.PersonalTokenRepository) *PersonalTokenService {
+	return &PersonalTokenService{repo: repo}
+}
+
+// Create gera um novo token pessoal. O valor em texto puro só é retornado aqui.
+// Contas somente leitura (readOnly) só podem criar tokens de leitura
+func (s *PersonalTokenService) Create(ctx context.Context, userID string, input model.CreatePersonalTokenInput, readOnly bool) (*model.CreatePersonalTokenResponse, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidTokenName
+	}
+
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly {
+		for _, scope := range scopes {
+			if scope == auth.ScopeExpensesWrite {
+				return nil, ErrWriteScopeNotAllowed
+			}
+		}
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidTokenExpiry
+	}
+
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	plain := auth.PersonalTokenPrefix + secret
+
+	token := &model.PersonalToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: auth.HashToken(plain),
+		Hint:      plain[:len(auth.PersonalTokenPrefix)+4],
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &model.CreatePersonalTokenResponse{PersonalToken: *token, Token: plain}, nil
+}
+
+// List lista os tokens pessoais do usuário, sem os valores dos tokens
+func (s *PersonalTokenService) List(ctx context.Context, userID string) ([]*model.PersonalToken, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Delete revoga um token pessoal do usuário
+func (s *PersonalTokenService) Delete(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrPersonalTokenNotFound
+	}
+
+	if err := s.repo.Delete(ctx, id, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPersonalTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteAll revoga todos os tokens pessoais do usuário
+func (s *PersonalTokenService) DeleteAll(ctx context.Context, userID string) error {
+	return s.repo.DeleteAllForUser(ctx, userID)
+}
+
+// Authenticate valida um token pessoal e retorna claims equivalentes às de um
+// JWT. O papel e a restrição de leitura do dono são preenchidos por
+// AuthService.Authenticate, que os lê a cada requisição
+func (s *PersonalTokenService) Authenticate(ctx context.Context, plain string) (*auth.Claims, error) {
+	token, err := s.repo.FindByHash(ctx, auth.HashToken(plain))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidPersonalToken
+		}
+		return nil, err
+	}
+
+	if token.IsExpired(time.Now()) {
+		return nil, ErrInvalidPersonalToken
+	}
+
+	// Falhar ao registrar o uso não deve impedir a requisição
+	if err := s.repo.TouchLastUsed(ctx, token.ID); err != nil {
+		log.Printf("Erro ao registrar uso do token pessoal %s: %v", token.ID, err)
+	}
+
+	claims := &auth.Claims{
+		UserID: token.UserID,
+		Type:   auth.TokenTypePersonal,
+		Scopes: token.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       token.ID,
+			IssuedAt: jwt.NewNumericDate(token.CreatedAt),
+		},
+	}
+	if token.ExpiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*token.ExpiresAt)
+	}
+	return claims, nil
+}
+
+// normalizeScopes valida os escopos solicitados e remove duplicatas
+func normalizeScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, ErrInvalidTokenScope
+	}
+
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, scope := range requested {
+		valid := false
+		for _, allowed := range auth.PersonalTokenScopes {
+			if scope == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, ErrInvalidTokenScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+// PrivacyService atende aos direitos do titular dos dados (LGPD/GDPR): a
+// exportação de todos os dados da conta e a exclusão da conta
+type PrivacyService struct {
+	userRepo      *repository.UserRepository
+	expenseRepo   repository.ExpenseRepository
+	authService   *AuthService
+	deletionGrace time.Duration
+}
+
+// NewPrivacyService cria uma nova instância do serviço de privacidade.
+// deletionGrace é o prazo até a exclusão definitiva de uma conta
+func NewPrivacyService(userRepo *repository.UserRepository, expenseRepo repository.ExpenseRepository, authService *AuthService, deletionGrace time.Duration) *PrivacyService {
+	return &PrivacyService{
+		userRepo:      userRepo,
+		expenseRepo:   expenseRepo,
+		authService:   authService,
+		deletionGrace: deletionGrace,
+	}
+}
+
+// Export reúne o perfil e todas as despesas do usuário
+func (s *PrivacyService) Export(ctx context.Context, userID string) (*model.AccountExport, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := s.expenseRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if expenses == nil {
+		expenses = []*model.Expense{}
+	}
+
+	return &model.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		User:       user,
+		Expenses:   expenses,
+	}, nil
+}
+
+// WriteArchive escreve em w um arquivo zip com a exportação: profile.json,
+// expenses.json e expenses.csv
+func (s *PrivacyService) WriteArchive(ctx context.Context, userID string, w io.Writer) error {
+	export, err := s.Export(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+
+	if err := writeZipJSON(archive, "profile.json", map[string]interface{}{
+		"exported_at": export.ExportedAt,
+		"user":        export.User,
+	}); err != nil {
+		return err
+	}
+	if err := writeZipJSON(archive, "expenses.json", export.Expenses); err != nil {
+		return err
+	}
+
+	file, err := archive.Create("expenses.csv")
+	if err != nil {
+		return err
+	}
+	if err := writeExpensesCSV(file, export.Expenses); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writeZipJSON(archive *zip.Writer, name string, v interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeExpensesCSV(w io.Writer, expenses []*model.Expense) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "date", "category", "amount", "currency", "description", "merchant", "notes", "tags", "created_at", "updated_at"}); err != nil {
+		return err
+	}
+	for _, expense := range expenses {
+		err := writer.Write([]string{
+			expense.ID,
+			expense.Date.Format("2006-01-02"),
+			string(expense.Category),
+			expense.Amount.String(),
+			expense.Currency,
+			csvText(expense.Description),
+			csvText(expense.Merchant),
+			csvText(expense.Notes),
+			csvText(strings.Join(expense.Tags, ";")),
+			expense.CreatedAt.UTC().Format(time.RFC3339),
+			expense.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText protege um texto livre do usuário contra injeção de fórmulas: células
+// que começam com =, +, -, @, tab ou CR são executadas por planilhas, então
+// recebem um apóstrofo na frente
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// RequestDeletion agenda a exclusão da conta após conferir a senha. Todas as
+// sessões são encerradas; um novo login dentro do prazo cancela a exclusão
+func (s *PrivacyService) RequestDeletion(ctx context.Context, userID string, input model.DeleteAccountInput) (*model.DeleteAccountResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.ComparePassword(input.Password); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	scheduledAt := time.Now().Add(s.deletionGrace).UTC().Truncate(time.Second)
+	if err := s.userRepo.ScheduleDeletion(ctx, user.ID, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.authService.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return &model.DeleteAccountResponse{DeletionScheduledAt: scheduledAt}, nil
+}
+
+// PurgeDeleted exclui definitivamente as contas cujo prazo de exclusão venceu
+func (s *PrivacyService) PurgeDeleted(ctx context.Context) (int64, error) {
+	return s.userRepo.DeleteScheduled(ctx, time.Now())
+}
+
+// Start executa a exclusão das contas vencidas periodicamente até o contexto
+// ser cancelado
+func (s *PrivacyService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.PurgeDeleted(ctx)
+			if err != nil {
+				log.Printf("Erro ao excluir contas agendadas: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("%d conta(s) excluída(s) definitivamente", deleted)
+			}
+		}
+	}
+}
+
+func (s *PrivacyService) findUser(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("sessão não encontrada")
+
+// maxUserAgentLength limita o user agent armazenado na sessão
+const maxUserAgentLength = 512
+
+// SessionService gerencia as sessões de login dos usuários
+type SessionService struct {
+	repo         *repository.SessionRepository
+	refreshRepo  *repository.RefreshTokenRepository
+	revocations  *auth.RevocationList
+	accessExpiry time.Duration
+}
+
+// NewSessionService cria uma nova instância do serviço de sessões. accessExpiry
+// é a validade dos tokens de acesso, pelo qual a revogação de uma sessão é mantida
+func NewSessionService(repo *repository.SessionRepository, refreshRepo *repository.RefreshTokenRepository, revocations *auth.RevocationList, accessExpiry time.Duration) *SessionService {
+	return &SessionService{
+		repo:         repo,
+		refreshRepo:  refreshRepo,
+		revocations:  revocations,
+		accessExpiry: accessExpiry,
+	}
+}
+
+// Start registra uma nova sessão e retorna o seu ID, que também identifica a
+// família de refresh tokens emitida para ela
+func (s *SessionService) Start(ctx context.Context, userID string, client model.ClientInfo) (string, error) {
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := &model.Session{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		UserAgent: userAgent,
+		IP:        client.IP,
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		return "", err
+	}
+	return session.ID, nil
+}
+
+// Touch registra o uso da sessão na renovação dos tokens
+func (s *SessionService) Touch(ctx context.Context, id string, client model.ClientInfo) error {
+	return s.repo.Touch(ctx, id, client.IP)
+}
+
+// List lista as sessões ativas do usuário, marcando a sessão atual
+func (s *SessionService) List(ctx context.Context, userID, currentID string) ([]model.Session, error) {
+	sessions, err := s.repo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke encerra uma sessão do usuário: seus refresh tokens deixam de ser
+// aceitos e os tokens de acesso já emitidos passam a ser recusados
+func (s *SessionService) Revoke(ctx context.Context, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := s.repo.Revoke(ctx, id, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeFamily(ctx, id); err != nil {
+		return err
+	}
+	return s.revocations.RevokeSession(ctx, id, userID, s.accessExpiry)
+}
+
+// RevokeAll encerra todas as sessões do usuário e revoga os tokens de acesso
+// de cada uma. A revogação por sessão cobre os tokens emitidos no mesmo segundo
+// do corte de AuthService.LogoutAll, que o iat em segundos não distingue
+func (s *SessionService) RevokeAll(ctx context.Context, userID string) error {
+	ids, err := s.repo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.revocations.RevokeSession(ctx, id, userID, s.accessExpiry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
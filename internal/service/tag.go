@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+// maxExpenseTags limita a quantidade de tags em uma despesa
+const maxExpenseTags = 20
+
+var (
+	ErrInvalidTag  = errors.New("tags devem ter entre 1 e 50 caracteres")
+	ErrTooManyTags = errors.New("uma despesa pode ter no máximo 20 tags")
+)
+
+// TagService gerencia as tags das despesas
+type TagService struct {
+	repo *repository.TagRepository
+}
+
+// NewTagService cria uma nova instância do serviço de tags
+func NewTagService(repo *repository.TagRepository) *TagService {
+	return &TagService{repo: repo}
+}
+
+// List lista as tags em uso pelo usuário com a quantidade de despesas de cada uma
+func (s *TagService) List(ctx context.Context, userID string) ([]model.TagUsage, error) {
+	return s.repo.ListUsage(ctx, userID)
+}
+
+// normalizeTags valida as tags de uma despesa e as converte para minúsculas,
+// sem espaços nas pontas e sem repetição, em ordem alfabética
+func normalizeTags(tags []string) ([]string, error) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > 50 {
+			return nil, ErrInvalidTag
+		}
+	}
+
+	normalized := cleanTags(tags)
+	if len(normalized) > maxExpenseTags {
+		return nil, ErrTooManyTags
+	}
+	return normalized, nil
+}
+
+// cleanTags normaliza as tags como normalizeTags, descartando as vazias
+func cleanTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+	sort.Strings(cleaned)
+	return cleaned
+}
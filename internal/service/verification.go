@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/mailer"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+)
+
+var (
+	ErrEmailNotVerified = errors.New("email não verificado")
+)
+
+// UnverifiedAccess define o que usuários com email não verificado podem fazer
+type UnverifiedAccess string
+
+const (
+	// UnverifiedAccessAllow permite acesso completo
+	UnverifiedAccessAllow UnverifiedAccess = "allow"
+	// UnverifiedAccessReadOnly permite login, mas apenas para leitura de dados
+	UnverifiedAccessReadOnly UnverifiedAccess = "read_only"
+	// UnverifiedAccessDeny impede o login até a verificação
+	UnverifiedAccessDeny UnverifiedAccess = "deny"
+)
+
+// VerificationService gerencia a verificação do email dos usuários
+type VerificationService struct {
+	userRepo  *repository.UserRepository
+	tokenRepo *repository.EmailTokenRepository
+	mailer    mailer.Mailer
+	appURL    string
+	tokenTTL  time.Duration
+}
+
+// NewVerificationService cria uma nova instância do serviço de verificação de email
+func NewVerificationService(userRepo *repository.UserRepository, tokenRepo *repository.EmailTokenRepository, mailer mailer.Mailer, appURL string, tokenTTL time.Duration) *VerificationService {
+	return &VerificationService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		appURL:    appURL,
+		tokenTTL:  tokenTTL,
+	}
+}
+
+// Send envia o link de verificação para o email do usuário, invalidando os anteriores
+func (s *VerificationService) Send(ctx context.Context, user *model.User) error {
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, model.EmailTokenVerifyEmail); err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.tokenRepo.Create(ctx, &model.EmailToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Purpose:   model.EmailTokenVerifyEmail,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirme o seu email",
+		Body: fmt.Sprintf(
+			"Bem-vindo! Confirme o seu email em até %s:\n%s\n\n"+
+				"Se você não criou uma conta, ignore este email.",
+			s.tokenTTL, link,
+		),
+	})
+}
+
+// Resend reenvia o link de verificação. Emails não cadastrados ou já
+// verificados e falhas no envio são ignorados silenciosamente para não revelar
+// quais emails existem
+func (s *VerificationService) Resend(ctx context.Context, input model.ResendVerificationInput) error {
+	user, err := s.userRepo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := s.Send(ctx, user); err != nil {
+		log.Printf("Erro ao reenviar email de verificação para %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+// Verify confirma o email a partir do token enviado
+func (s *VerificationService) Verify(ctx context.Context, input model.ConfirmEmailInput) (*model.User, error) {
+	stored, err := consumeEmailToken(ctx, s.tokenRepo, input.Token, model.EmailTokenVerifyEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	// O email pode ter sido trocado depois do envio do link
+	if user.Email != stored.Email {
+		return nil, ErrInvalidEmailToken
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.FindByID(ctx, user.ID)
+}
+
+// consumeEmailToken valida e marca como utilizado um token enviado por email
+func consumeEmailToken(ctx context.Context, repo *repository.EmailTokenRepository, token string, purpose model.EmailTokenPurpose) (*model.EmailToken, error) {
+	stored, err := repo.FindByHash(ctx, auth.HashToken(token), purpose)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidEmailToken
+	}
+
+	used, err := repo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidEmailToken
+	}
+
+	return stored, nil
+}
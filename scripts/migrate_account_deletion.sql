@@ -0,0 +1,7 @@
+-- Adiciona a exclusão agendada de contas a um banco existente. Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_account_deletion.sql
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
@@ -0,0 +1,67 @@
+-- Migra um banco existente das categorias fixas (tipo expense_category) para as
+-- categorias por usuário. Execute uma única vez, após atualizar a aplicação:
+--   psql -U expense_user -d expense_db -f scripts/migrate_categories.sql
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7),
+    icon VARCHAR(50),
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_name ON categories(user_id, lower(name));
+
+CREATE OR REPLACE FUNCTION seed_default_categories(owner UUID)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO categories (user_id, name)
+    SELECT owner, name
+    FROM unnest(ARRAY['MANTIMENTOS', 'LAZER', 'ELETRONICA', 'UTILITARIOS', 'ROUPAS', 'SAUDE', 'OUTROS']) AS name
+    ON CONFLICT DO NOTHING;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION seed_default_categories_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM seed_default_categories(NEW.id);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS seed_user_categories ON users;
+CREATE TRIGGER seed_user_categories
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION seed_default_categories_trigger();
+
+CREATE TRIGGER update_categories_updated_at
+    BEFORE UPDATE ON categories
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Cria as categorias padrão dos usuários existentes
+SELECT seed_default_categories(id) FROM users;
+
+-- Associa cada despesa à categoria de mesmo nome do seu dono
+ALTER TABLE expenses ADD COLUMN category_id UUID REFERENCES categories(id);
+
+UPDATE expenses e
+SET category_id = c.id
+FROM categories c
+WHERE c.user_id = e.user_id AND c.name = e.category::text;
+
+ALTER TABLE expenses ALTER COLUMN category_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_expenses_category;
+ALTER TABLE expenses DROP COLUMN category;
+DROP TYPE expense_category;
+
+CREATE INDEX IF NOT EXISTS idx_expenses_category_id ON expenses(category_id);
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- Adiciona as moedas e as cotações a um banco existente. Execute uma única vez,
+-- antes de scripts/migrate_categories.sql:
+--   psql -U expense_user -d expense_db -f scripts/migrate_currency.sql
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS base_currency CHAR(3) NOT NULL DEFAULT 'BRL';
+
+-- Despesas existentes ficam na moeda padrão
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'BRL';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) NOT NULL,
+    date DATE NOT NULL,
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    PRIMARY KEY (currency, date)
+);
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Torna os emails únicos sem diferenciar maiúsculas em um banco existente e os
+-- grava em minúsculas. Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_email_lookup.sql
+BEGIN;
+
+-- Contas com emails que diferem apenas nas maiúsculas precisam ser unificadas
+-- (ou ter o email alterado) antes da migração
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(email, ', ') INTO duplicates
+    FROM (SELECT lower(trim(email)) AS email FROM users GROUP BY 1 HAVING count(*) > 1) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'emails duplicados sem diferenciar maiúsculas: %', duplicates;
+    END IF;
+END
+$$;
+
+UPDATE users SET email = lower(trim(email)) WHERE email <> lower(trim(email));
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE UNIQUE INDEX idx_users_email_lower ON users(lower(email));
+
+COMMIT;
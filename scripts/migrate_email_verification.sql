@@ -0,0 +1,11 @@
+-- Adiciona a verificação de email a um banco existente. Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_email_verification.sql
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Contas criadas antes da verificação não recebem o email de confirmação e
+-- são consideradas verificadas
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+-- Adiciona o índice da paginação das despesas a um banco existente. Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_pagination.sql
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_expenses_user_date_id ON expenses(user_id, date DESC, id DESC);
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Adiciona os papéis e a desativação de contas a um banco existente. Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_roles.sql
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'admin', 'read_only'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
@@ -0,0 +1,30 @@
+-- Adiciona o estabelecimento, as observações e a busca textual das despesas a um
+-- banco existente. Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_search.sql
+-- A coluna gerada é calculada para todas as despesas existentes, o que reescreve
+-- a tabela e a mantém bloqueada durante a migração
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'portuguese_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION portuguese_unaccent (COPY = portuguese);
+        ALTER TEXT SEARCH CONFIGURATION portuguese_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, portuguese_stem;
+    END IF;
+END
+$$;
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS merchant VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('portuguese_unaccent', description), 'A') ||
+    setweight(to_tsvector('portuguese_unaccent', merchant), 'B') ||
+    setweight(to_tsvector('portuguese_unaccent', notes), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_expenses_search ON expenses USING GIN (search_vector);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Adiciona as subcategorias a um banco já migrado para as categorias por usuário
+-- (scripts/migrate_categories.sql). Execute uma única vez:
+--   psql -U expense_user -d expense_db -f scripts/migrate_subcategories.sql
+BEGIN;
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories(id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+COMMIT;
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountEndpoints(t *testing.T) {
+	require.NoError(t, cleanDatabase())
+
+	srv := setupTestServer(t, testDB)
+	ctx := context.Background()
+
+	credentials := model.CreateUserInput{Email: "account@example.com", Password: "password123"}
+	_, err := srv.authService.Register(ctx, credentials)
+	require.NoError(t, err)
+	login, err := srv.authService.Login(ctx, model.LoginInput{Email: credentials.Email, Password: credentials.Password})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/me", middleware.AuthMiddleware(srv.authService, srv.accountHandler.Profile))
+	mux.HandleFunc("PUT /api/v1/me/password", middleware.AuthMiddleware(srv.authService, srv.accountHandler.ChangePassword))
+	mux.HandleFunc("PUT /api/v1/me/email", middleware.AuthMiddleware(srv.authService, srv.accountHandler.ChangeEmail))
+	mux.HandleFunc("POST /api/v1/auth/email/confirm", srv.accountHandler.ConfirmEmail)
+
+	request := func(method, path, token string, input interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("deve retornar o perfil", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/me", login.Token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var user model.User
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&user))
+		assert.Equal(t, credentials.Email, user.Email)
+	})
+
+	t.Run("deve rejeitar troca de senha com senha atual incorreta", func(t *testing.T) {
+		w := request(http.MethodPut, "/api/v1/me/password", login.Token, model.ChangePasswordInput{
+			CurrentPassword: "wrong_password",
+			NewPassword:     "newpassword123",
+		})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("deve trocar a senha e encerrar as sessões anteriores", func(t *testing.T) {
+		w := request(http.MethodPut, "/api/v1/me/password", login.Token, model.ChangePasswordInput{
+			CurrentPassword: credentials.Password,
+			NewPassword:     "newpassword123",
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response model.LoginResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/me", login.Token, nil).Code)
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/me", response.Token, nil).Code)
+		login = &response
+	})
+
+	t.Run("deve trocar o email após a confirmação", func(t *testing.T) {
+		// O email é gravado em minúsculas
+		w := request(http.MethodPut, "/api/v1/me/email", login.Token, model.ChangeEmailInput{
+			Email:    "New-Account@Example.com",
+			Password: "newpassword123",
+		})
+		require.Equal(t, http.StatusAccepted, w.Code)
+		msg := srv.mailer.last()
+		require.NotNil(t, msg)
+		assert.Equal(t, "new-account@example.com", msg.To)
+
+		w = request(http.MethodPost, "/api/v1/auth/email/confirm", "", model.ConfirmEmailInput{Token: tokenFromLink(t, msg)})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var user model.User
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&user))
+		assert.Equal(t, "new-account@example.com", user.Email)
+	})
+}
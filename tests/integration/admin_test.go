@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/handler"
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+	"expenseapi/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminEndpoints(t *testing.T) {
+	require.NoError(t, cleanDatabase())
+
+	srv := setupTestServer(t, testDB)
+	ctx := context.Background()
+	userRepo := repository.NewUserRepository(testDB)
+
+	adminUser, err := srv.authService.Register(ctx, model.CreateUserInput{Email: "admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NoError(t, userRepo.UpdateRole(ctx, adminUser.ID, model.RoleAdmin))
+	adminLogin, err := srv.authService.Login(ctx, model.LoginInput{Email: "admin@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	userCredentials := model.LoginInput{Email: "user@example.com", Password: "password123"}
+	user, err := srv.authService.Register(ctx, model.CreateUserInput{Email: userCredentials.Email, Password: userCredentials.Password})
+	require.NoError(t, err)
+	userLogin, err := srv.authService.Login(ctx, userCredentials)
+	require.NoError(t, err)
+
+	adminHandler := handler.NewAdminHandler(service.NewAdminService(userRepo, srv.authService))
+	admin := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.AuthMiddleware(srv.authService, middleware.RequireRole(model.RoleAdmin, next))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/me", middleware.AuthMiddleware(srv.authService, srv.accountHandler.Profile))
+	mux.HandleFunc("GET /api/v1/admin/users", admin(adminHandler.ListUsers))
+	mux.HandleFunc("PUT /api/v1/admin/users/{id}/role", admin(adminHandler.SetRole))
+	mux.HandleFunc("POST /api/v1/admin/users/{id}/disable", admin(adminHandler.Disable))
+	mux.HandleFunc("POST /api/v1/admin/users/{id}/enable", admin(adminHandler.Enable))
+
+	request := func(method, path, token string, input interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("deve listar usuários apenas para administradores", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api/v1/admin/users", userLogin.Token, nil).Code)
+
+		w := request(http.MethodGet, "/api/v1/admin/users", adminLogin.Token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var users []model.User
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&users))
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("deve impedir que o administrador desative a própria conta", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/admin/users/"+adminUser.ID+"/disable", adminLogin.Token, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deve desativar a conta e rejeitar os tokens do usuário", func(t *testing.T) {
+		personal, err := service.NewPersonalTokenService(repository.NewPersonalTokenRepository(testDB)).
+			Create(ctx, user.ID, model.CreatePersonalTokenInput{Name: "script", Scopes: []string{auth.ScopeExpensesRead}}, false)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusNoContent, request(http.MethodPost, "/api/v1/admin/users/"+user.ID+"/disable", adminLogin.Token, nil).Code)
+
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/me", userLogin.Token, nil).Code)
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/me", personal.Token, nil).Code)
+		_, err = srv.authService.Authenticate(ctx, personal.Token)
+		assert.ErrorIs(t, err, service.ErrAccountDisabled)
+		_, err = srv.authService.Login(ctx, userCredentials)
+		assert.ErrorIs(t, err, service.ErrAccountDisabled)
+	})
+
+	t.Run("deve reativar a conta", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, request(http.MethodPost, "/api/v1/admin/users/"+user.ID+"/enable", adminLogin.Token, nil).Code)
+
+		_, err := srv.authService.Login(ctx, userCredentials)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deve rejeitar o token de acesso assim que a conta é desativada", func(t *testing.T) {
+		login, err := srv.authService.Login(ctx, userCredentials)
+		require.NoError(t, err)
+
+		// Desativa direto no banco, sem passar pela revogação dos tokens
+		require.NoError(t, userRepo.SetDisabled(ctx, user.ID, true))
+		_, err = srv.authService.Authenticate(ctx, login.Token)
+		assert.ErrorIs(t, err, service.ErrAccountDisabled)
+
+		require.NoError(t, userRepo.SetDisabled(ctx, user.ID, false))
+		_, err = srv.authService.Authenticate(ctx, login.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deve aplicar o papel somente leitura nos novos tokens", func(t *testing.T) {
+		w := request(http.MethodPut, "/api/v1/admin/users/"+user.ID+"/role", adminLogin.Token, model.UpdateRoleInput{Role: model.RoleReadOnly})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		login, err := srv.authService.Login(ctx, userCredentials)
+		require.NoError(t, err)
+		claims, err := srv.authService.Authenticate(ctx, login.Token)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleReadOnly, claims.Role)
+		assert.True(t, claims.ReadOnly)
+	})
+}
@@ -2,36 +2,127 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"expenseapi/internal/auth"
 	"expenseapi/internal/handler"
+	"expenseapi/internal/mailer"
+	"expenseapi/internal/middleware"
 	"expenseapi/internal/model"
 	"expenseapi/internal/repository"
 	"expenseapi/internal/service"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type testServer struct {
-	db          *pgxpool.Pool
-	authHandler *handler.AuthHandler
+	db                   *pgxpool.Pool
+	authService          *service.AuthService
+	authHandler          *handler.AuthHandler
+	passwordResetHandler *handler.PasswordResetHandler
+	accountHandler       *handler.AccountHandler
+	verificationHandler  *handler.VerificationHandler
+	mfaHandler           *handler.MFAHandler
+	sessionHandler       *handler.SessionHandler
+	mailer               *captureMailer
+}
+
+// captureMailer guarda os emails enviados para inspeção nos testes. Com err
+// preenchido, simula uma falha no envio
+type captureMailer struct {
+	messages []mailer.Message
+	err      error
+}
+
+func (m *captureMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// last retorna o último email enviado
+func (m *captureMailer) last() *mailer.Message {
+	if len(m.messages) == 0 {
+		return nil
+	}
+	return &m.messages[len(m.messages)-1]
+}
+
+// tokenFromLink extrai o token do link contido no corpo do email
+func tokenFromLink(t *testing.T, msg *mailer.Message) string {
+	t.Helper()
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatal("link com token não encontrado no email")
+	}
+	token, _ := url.QueryUnescape(match[1])
+	return token
+}
+
+// newTestLoginLimiter cria um limitador que bloqueia a conta após três falhas
+func newTestLoginLimiter(db *pgxpool.Pool) *auth.LoginLimiter {
+	return auth.NewLoginLimiter(repository.NewLoginAttemptRepository(db),
+		auth.LimitPolicy{Threshold: 3, BaseDelay: time.Minute, MaxDelay: 15 * time.Minute, Window: time.Hour},
+		auth.LimitPolicy{Threshold: 20, BaseDelay: time.Minute, MaxDelay: 15 * time.Minute, Window: time.Hour},
+	)
+}
+
+// newTestPasswordPolicy cria a política padrão: mínimo de seis caracteres, sem
+// classes exigidas e sem lista de senhas vazadas
+func newTestPasswordPolicy(t *testing.T) *service.PasswordPolicy {
+	t.Helper()
+	policy, err := service.NewPasswordPolicy(6, nil, nil)
+	if err != nil {
+		t.Fatalf("erro ao criar política de senhas: %v", err)
+	}
+	return policy
 }
 
 func setupTestServer(t *testing.T, db *pgxpool.Pool) *testServer {
 	t.Helper()
+	return setupTestServerWithAccess(t, db, service.UnverifiedAccessAllow)
+}
+
+func setupTestServerWithAccess(t *testing.T, db *pgxpool.Pool, unverifiedAccess service.UnverifiedAccess) *testServer {
+	t.Helper()
 
 	// Inicializa os serviços
-	jwtService := auth.NewJWTService("test_secret_key", 24*60*60, 7*24*60*60)
+	jwtService := auth.NewJWTService("test_secret_key", 24*time.Hour, 7*24*time.Hour)
 	userRepo := repository.NewUserRepository(db)
-	authService := service.NewAuthService(userRepo, jwtService)
+	refreshRepo := repository.NewRefreshTokenRepository(db)
+	revocations := auth.NewRevocationList(repository.NewRevocationRepository(db))
+	capture := &captureMailer{}
+	emailTokenRepo := repository.NewEmailTokenRepository(db)
+	verificationService := service.NewVerificationService(userRepo, emailTokenRepo, capture, "http://localhost:3000", time.Hour)
+	sessionService := service.NewSessionService(repository.NewSessionRepository(db), refreshRepo, revocations, jwtService.AccessExpiry())
+	authService := service.NewAuthService(userRepo, refreshRepo, jwtService, revocations, verificationService, unverifiedAccess, newTestLoginLimiter(db), service.NewPersonalTokenService(repository.NewPersonalTokenRepository(db)), sessionService, newTestPasswordPolicy(t))
 	authHandler := handler.NewAuthHandler(authService)
 
+	resetRepo := repository.NewPasswordResetRepository(db)
+	passwordResetService := service.NewPasswordResetService(userRepo, resetRepo, authService, capture, "http://localhost:3000", time.Hour)
+	accountService := service.NewAccountService(userRepo, emailTokenRepo, authService, capture, "http://localhost:3000", time.Hour)
+
 	return &testServer{
-		db:          db,
-		authHandler: authHandler,
+		db:                   db,
+		authService:          authService,
+		authHandler:          authHandler,
+		passwordResetHandler: handler.NewPasswordResetHandler(passwordResetService),
+		accountHandler:       handler.NewAccountHandler(accountService),
+		verificationHandler:  handler.NewVerificationHandler(verificationService),
+		mfaHandler:           handler.NewMFAHandler(service.NewMFAService(userRepo, repository.NewMFARepository(db), jwtService, authService, newTestLoginLimiter(db), "Expense API")),
+		sessionHandler:       handler.NewSessionHandler(authService),
+		mailer:               capture,
 	}
 }
 
@@ -55,6 +146,15 @@ func TestRegister(t *testing.T) {
 			},
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name: "email_existente_com_outras_maiusculas",
+			input: model.CreateUserInput{
+				Email:    "Test@Example.com",
+				Password: "password123",
+			},
+			expectedCode:  http.StatusConflict,
+			expectedError: "Usuário já existe",
+		},
 		{
 			name: "email_invalido",
 			input: model.CreateUserInput{
@@ -214,3 +314,352 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginRateLimit(t *testing.T) {
+	if err := cleanDatabase(); err != nil {
+		t.Fatalf("erro ao limpar banco de dados: %v", err)
+	}
+
+	srv := setupTestServer(t, testDB)
+	if _, err := srv.authService.Register(context.Background(), model.CreateUserInput{Email: "limit@example.com", Password: "password123"}); err != nil {
+		t.Fatalf("erro ao criar usuário de teste: %v", err)
+	}
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(model.LoginInput{Email: "limit@example.com", Password: password})
+		w := httptest.NewRecorder()
+		srv.authHandler.Login(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body)))
+		return w
+	}
+
+	// As falhas até o limite e a que o excede ainda recebem 401
+	for i := 0; i < 4; i++ {
+		if w := login("wrong_password"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("tentativa %d: código de status esperado %d, obtido %d", i+1, http.StatusUnauthorized, w.Code)
+		}
+	}
+
+	// Com a conta bloqueada, nem a senha correta é aceita
+	w := login("password123")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("código de status esperado %d, obtido %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("header Retry-After não retornado")
+	}
+
+	var events int
+	if err := testDB.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM login_events WHERE email = 'limit@example.com'`,
+	).Scan(&events); err != nil {
+		t.Fatalf("erro ao contar eventos de login: %v", err)
+	}
+	if events != 5 {
+		t.Errorf("eventos de login esperados 5, obtidos %d", events)
+	}
+}
+
+func TestPasswordRehash(t *testing.T) {
+	if err := cleanDatabase(); err != nil {
+		t.Fatalf("erro ao limpar banco de dados: %v", err)
+	}
+
+	srv := setupTestServer(t, testDB)
+	ctx := context.Background()
+	credentials := model.LoginInput{Email: "rehash@example.com", Password: "password123"}
+	user, err := srv.authService.Register(ctx, model.CreateUserInput{Email: credentials.Email, Password: credentials.Password})
+	if err != nil {
+		t.Fatalf("erro ao criar usuário de teste: %v", err)
+	}
+
+	// Simula uma conta criada antes da migração, com hash bcrypt
+	legacy, err := bcrypt.GenerateFromPassword([]byte(credentials.Password), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("erro ao gerar hash bcrypt: %v", err)
+	}
+	userRepo := repository.NewUserRepository(testDB)
+	if err := userRepo.UpdatePassword(ctx, user.ID, string(legacy)); err != nil {
+		t.Fatalf("erro ao gravar hash legado: %v", err)
+	}
+
+	if _, err := srv.authService.Login(ctx, credentials); err != nil {
+		t.Fatalf("login com hash legado falhou: %v", err)
+	}
+
+	stored, err := userRepo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("erro ao buscar usuário: %v", err)
+	}
+	if !strings.HasPrefix(stored.PasswordHash, "$argon2id$") {
+		t.Errorf("esperado hash Argon2id após o login, obtido %q", stored.PasswordHash[:4])
+	}
+
+	// A senha continua válida com o novo hash
+	if _, err := srv.authService.Login(ctx, credentials); err != nil {
+		t.Errorf("login após a atualização do hash falhou: %v", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	// Limpa o banco antes dos testes
+	if err := cleanDatabase(); err != nil {
+		t.Fatalf("erro ao limpar banco de dados: %v", err)
+	}
+
+	srv := setupTestServer(t, testDB)
+
+	// Registra e autentica um usuário para obter o primeiro refresh token
+	credentials := model.CreateUserInput{Email: "refresh@example.com", Password: "password123"}
+	body, _ := json.Marshal(credentials)
+	w := httptest.NewRecorder()
+	srv.authHandler.Register(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("erro ao criar usuário de teste: código %d", w.Code)
+	}
+
+	body, _ = json.Marshal(model.LoginInput{Email: credentials.Email, Password: credentials.Password})
+	w = httptest.NewRecorder()
+	srv.authHandler.Login(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body)))
+	var login model.LoginResponse
+	if err := json.NewDecoder(w.Body).Decode(&login); err != nil {
+		t.Fatalf("erro ao decodificar resposta: %v", err)
+	}
+
+	refresh := func(token string) (*httptest.ResponseRecorder, model.LoginResponse) {
+		body, _ := json.Marshal(model.RefreshTokenInput{RefreshToken: token})
+		w := httptest.NewRecorder()
+		srv.authHandler.Refresh(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewBuffer(body)))
+		var response model.LoginResponse
+		if w.Code == http.StatusOK {
+			json.NewDecoder(w.Body).Decode(&response)
+		}
+		return w, response
+	}
+
+	// Primeira troca gera um novo par
+	w, rotated := refresh(login.RefreshToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("código de status esperado %d, obtido %d", http.StatusOK, w.Code)
+	}
+	if rotated.RefreshToken == "" || rotated.RefreshToken == login.RefreshToken {
+		t.Fatal("refresh token não foi rotacionado")
+	}
+
+	// Reutilizar o token antigo é detectado
+	if w, _ := refresh(login.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusUnauthorized, w.Code)
+	}
+
+	// A família inteira foi revogada, inclusive o token mais recente
+	if w, _ := refresh(rotated.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	// Limpa o banco antes dos testes
+	if err := cleanDatabase(); err != nil {
+		t.Fatalf("erro ao limpar banco de dados: %v", err)
+	}
+
+	srv := setupTestServer(t, testDB)
+	credentials := model.LoginInput{Email: "logout@example.com", Password: "password123"}
+	if _, err := srv.authService.Register(context.Background(), model.CreateUserInput{Email: credentials.Email, Password: credentials.Password}); err != nil {
+		t.Fatalf("erro ao criar usuário de teste: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/logout", middleware.AuthMiddleware(srv.authService, middleware.RequireSession(srv.authHandler.Logout)))
+	mux.HandleFunc("POST /api/v1/auth/logout-all", middleware.AuthMiddleware(srv.authService, middleware.RequireSession(srv.authHandler.LogoutAll)))
+	mux.HandleFunc("GET /api/v1/me/sessions", middleware.AuthMiddleware(srv.authService, middleware.RequireSession(srv.sessionHandler.List)))
+
+	login := func() string {
+		response, err := srv.authService.Login(context.Background(), credentials)
+		if err != nil {
+			t.Fatalf("erro ao autenticar: %v", err)
+		}
+		return response.Token
+	}
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("deve revogar o token atual no logout", func(t *testing.T) {
+		token := login()
+		if code := request(http.MethodGet, "/api/v1/me/sessions", token); code != http.StatusOK {
+			t.Fatalf("código de status esperado %d, obtido %d", http.StatusOK, code)
+		}
+		if code := request(http.MethodPost, "/api/v1/auth/logout", token); code != http.StatusNoContent {
+			t.Fatalf("código de status esperado %d, obtido %d", http.StatusNoContent, code)
+		}
+		if code := request(http.MethodGet, "/api/v1/me/sessions", token); code != http.StatusUnauthorized {
+			t.Errorf("código de status esperado %d, obtido %d", http.StatusUnauthorized, code)
+		}
+	})
+
+	t.Run("deve encerrar a sessão no logout sem refresh token", func(t *testing.T) {
+		response, err := srv.authService.Login(context.Background(), credentials)
+		if err != nil {
+			t.Fatalf("erro ao autenticar: %v", err)
+		}
+		if code := request(http.MethodPost, "/api/v1/auth/logout", response.Token); code != http.StatusNoContent {
+			t.Fatalf("código de status esperado %d, obtido %d", http.StatusNoContent, code)
+		}
+
+		_, err = srv.authService.Refresh(context.Background(), model.RefreshTokenInput{RefreshToken: response.RefreshToken}, model.ClientInfo{})
+		if !errors.Is(err, service.ErrInvalidRefresh) {
+			t.Errorf("erro esperado %v, obtido %v", service.ErrInvalidRefresh, err)
+		}
+	})
+
+	t.Run("deve revogar tokens emitidos no mesmo segundo do logout geral", func(t *testing.T) {
+		// Os dois tokens costumam ter o mesmo iat do corte da revogação
+		first, second := login(), login()
+		if code := request(http.MethodPost, "/api/v1/auth/logout-all", first); code != http.StatusNoContent {
+			t.Fatalf("código de status esperado %d, obtido %d", http.StatusNoContent, code)
+		}
+		for _, token := range []string{first, second} {
+			if code := request(http.MethodGet, "/api/v1/me/sessions", token); code != http.StatusUnauthorized {
+				t.Errorf("código de status esperado %d, obtido %d", http.StatusUnauthorized, code)
+			}
+		}
+
+		// Um novo login continua funcionando logo após o logout geral
+		if code := request(http.MethodGet, "/api/v1/me/sessions", login()); code != http.StatusOK {
+			t.Errorf("código de status esperado %d, obtido %d", http.StatusOK, code)
+		}
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	// Limpa o banco antes dos testes
+	if err := cleanDatabase(); err != nil {
+		t.Fatalf("erro ao limpar banco de dados: %v", err)
+	}
+
+	srv := setupTestServer(t, testDB)
+
+	credentials := model.CreateUserInput{Email: "reset@example.com", Password: "password123"}
+	body, _ := json.Marshal(credentials)
+	w := httptest.NewRecorder()
+	srv.authHandler.Register(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("erro ao criar usuário de teste: código %d", w.Code)
+	}
+
+	// Solicita a redefinição e extrai o token do link enviado por email
+	body, _ = json.Marshal(model.ForgotPasswordInput{Email: credentials.Email})
+	w = httptest.NewRecorder()
+	srv.passwordResetHandler.Forgot(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/forgot", bytes.NewBuffer(body)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("código de status esperado %d, obtido %d", http.StatusAccepted, w.Code)
+	}
+	msg := srv.mailer.last()
+	if msg == nil || msg.Subject != "Redefinição de senha" {
+		t.Fatal("email de redefinição não enviado")
+	}
+
+	token := tokenFromLink(t, msg)
+
+	reset := func() int {
+		body, _ := json.Marshal(model.ResetPasswordInput{Token: token, Password: "newpassword123"})
+		w := httptest.NewRecorder()
+		srv.passwordResetHandler.Reset(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/reset", bytes.NewBuffer(body)))
+		return w.Code
+	}
+
+	if code := reset(); code != http.StatusNoContent {
+		t.Fatalf("código de status esperado %d, obtido %d", http.StatusNoContent, code)
+	}
+
+	// O token é de uso único
+	if code := reset(); code != http.StatusBadRequest {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusBadRequest, code)
+	}
+
+	// A nova senha passa a valer
+	body, _ = json.Marshal(model.LoginInput{Email: credentials.Email, Password: "newpassword123"})
+	w = httptest.NewRecorder()
+	srv.authHandler.Login(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusOK, w.Code)
+	}
+
+	// Uma falha no envio do email não altera a resposta
+	srv.mailer.err = errors.New("servidor SMTP indisponível")
+	body, _ = json.Marshal(model.ForgotPasswordInput{Email: credentials.Email})
+	w = httptest.NewRecorder()
+	srv.passwordResetHandler.Forgot(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/forgot", bytes.NewBuffer(body)))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusAccepted, w.Code)
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	// Limpa o banco antes dos testes
+	if err := cleanDatabase(); err != nil {
+		t.Fatalf("erro ao limpar banco de dados: %v", err)
+	}
+
+	// Usuários não verificados não podem fazer login
+	srv := setupTestServerWithAccess(t, testDB, service.UnverifiedAccessDeny)
+
+	credentials := model.CreateUserInput{Email: "verify@example.com", Password: "password123"}
+	body, _ := json.Marshal(credentials)
+	w := httptest.NewRecorder()
+	srv.authHandler.Register(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("erro ao criar usuário de teste: código %d", w.Code)
+	}
+
+	msg := srv.mailer.last()
+	if msg == nil || msg.To != credentials.Email {
+		t.Fatal("email de verificação não enviado")
+	}
+
+	login := func() int {
+		body, _ := json.Marshal(model.LoginInput{Email: credentials.Email, Password: credentials.Password})
+		w := httptest.NewRecorder()
+		srv.authHandler.Login(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body)))
+		return w.Code
+	}
+
+	if code := login(); code != http.StatusForbidden {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusForbidden, code)
+	}
+
+	// Uma falha no reenvio responde como um email desconhecido
+	srv.mailer.err = errors.New("servidor SMTP indisponível")
+	for _, email := range []string{credentials.Email, "unknown@example.com"} {
+		body, _ = json.Marshal(model.ResendVerificationInput{Email: email})
+		w = httptest.NewRecorder()
+		srv.verificationHandler.Resend(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/resend-verification", bytes.NewBuffer(body)))
+		if w.Code != http.StatusAccepted {
+			t.Errorf("%s: código de status esperado %d, obtido %d", email, http.StatusAccepted, w.Code)
+		}
+	}
+	srv.mailer.err = nil
+
+	// O reenvio invalida o link anterior; vale o do novo email
+	body, _ = json.Marshal(model.ResendVerificationInput{Email: credentials.Email})
+	w = httptest.NewRecorder()
+	srv.verificationHandler.Resend(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/resend-verification", bytes.NewBuffer(body)))
+	if msg = srv.mailer.last(); msg == nil || len(srv.mailer.messages) != 2 {
+		t.Fatal("email de verificação não reenviado")
+	}
+
+	body, _ = json.Marshal(model.ConfirmEmailInput{Token: tokenFromLink(t, msg)})
+	w = httptest.NewRecorder()
+	srv.verificationHandler.Verify(w, httptest.NewRequest(http.MethodPost, "/api/v1/auth/verify-email", bytes.NewBuffer(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("código de status esperado %d, obtido %d", http.StatusOK, w.Code)
+	}
+
+	if code := login(); code != http.StatusOK {
+		t.Errorf("código de status esperado %d, obtido %d", http.StatusOK, code)
+	}
+}
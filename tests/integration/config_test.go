@@ -58,6 +58,8 @@ func cleanDatabase() error {
 	queries := []string{
 		"TRUNCATE users CASCADE",
 		"TRUNCATE expenses CASCADE",
+		"TRUNCATE login_attempts, login_events, oidc_states",
+		"TRUNCATE exchange_rates",
 	}
 
 	for _, query := range queries {
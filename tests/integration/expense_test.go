@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"expenseapi/internal/auth"
 	"expenseapi/internal/config"
+	"expenseapi/internal/currency"
 	"expenseapi/internal/handler"
+	"expenseapi/internal/mailer"
 	"expenseapi/internal/middleware"
 	"expenseapi/internal/model"
 	"expenseapi/internal/repository"
@@ -24,11 +28,12 @@ import (
 )
 
 type expenseTestServer struct {
-	db          *pgxpool.Pool
-	jwtService  *auth.JWTService
-	authToken   string
-	userID      string
-	httpHandler http.Handler
+	db              *pgxpool.Pool
+	jwtService      *auth.JWTService
+	currencyService *service.CurrencyService
+	authToken       string
+	userID          string
+	httpHandler     http.Handler
 }
 
 func setupExpenseTestServer(t *testing.T) *expenseTestServer {
@@ -48,12 +53,25 @@ func setupExpenseTestServer(t *testing.T) *expenseTestServer {
 	// Configura os serviços
 	jwtService := auth.NewJWTService(cfg.JWT.Secret, cfg.JWT.ExpiresIn, cfg.JWT.RefreshToken)
 	userRepo := repository.NewUserRepository(dbpool)
-	authService := service.NewAuthService(userRepo, jwtService)
+	refreshRepo := repository.NewRefreshTokenRepository(dbpool)
+	revocations := auth.NewRevocationList(repository.NewRevocationRepository(dbpool))
+	verificationService := service.NewVerificationService(userRepo, repository.NewEmailTokenRepository(dbpool), mailer.NewLogMailer(""), cfg.App.URL, cfg.Auth.EmailTokenTTL)
+	personalTokenService := service.NewPersonalTokenService(repository.NewPersonalTokenRepository(dbpool))
+	personalTokenHandler := handler.NewPersonalTokenHandler(personalTokenService)
+	sessionService := service.NewSessionService(repository.NewSessionRepository(dbpool), refreshRepo, revocations, jwtService.AccessExpiry())
+	authService := service.NewAuthService(userRepo, refreshRepo, jwtService, revocations, verificationService, service.UnverifiedAccessAllow, newTestLoginLimiter(dbpool), personalTokenService, sessionService, newTestPasswordPolicy(t))
 	authHandler := handler.NewAuthHandler(authService)
 
+	currencyService := service.NewCurrencyService(currency.NewTable(), repository.NewExchangeRateRepository(dbpool), userRepo)
+	currencyHandler := handler.NewCurrencyHandler(currencyService)
+
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(dbpool))
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+
 	expenseRepo := repository.NewExpenseRepository(dbpool)
-	expenseService := service.NewExpenseService(expenseRepo)
+	expenseService := service.NewExpenseService(expenseRepo, categoryService, currencyService)
 	expenseHandler := handler.NewExpenseHandler(expenseService)
+	tagHandler := handler.NewTagHandler(service.NewTagService(repository.NewTagRepository(dbpool)))
 
 	// Cria um usuário de teste
 	email := "test@example.com"
@@ -77,20 +95,39 @@ func setupExpenseTestServer(t *testing.T) *expenseTestServer {
 	// Rotas de autenticação
 	mux.HandleFunc("POST /api/v1/auth/register", authHandler.Register)
 	mux.HandleFunc("POST /api/v1/auth/login", authHandler.Login)
+	mux.HandleFunc("POST /api/v1/auth/logout", middleware.AuthMiddleware(authService, middleware.RequireSession(authHandler.Logout)))
+	mux.HandleFunc("POST /api/v1/auth/logout-all", middleware.AuthMiddleware(authService, middleware.RequireSession(authHandler.LogoutAll)))
+
+	// Rotas de tokens pessoais
+	mux.HandleFunc("POST /api/v1/me/tokens", middleware.AuthMiddleware(authService, middleware.RequireSession(personalTokenHandler.Create)))
+	mux.HandleFunc("GET /api/v1/me/tokens", middleware.AuthMiddleware(authService, middleware.RequireSession(personalTokenHandler.List)))
+	mux.HandleFunc("DELETE /api/v1/me/tokens/{id}", middleware.AuthMiddleware(authService, middleware.RequireSession(personalTokenHandler.Delete)))
+
+	mux.HandleFunc("PUT /api/v1/me/currency", middleware.AuthMiddleware(authService, middleware.RequireSession(currencyHandler.SetBaseCurrency)))
 
 	// Rotas de despesas (protegidas por autenticação)
-	mux.HandleFunc("POST /api/v1/expenses", middleware.AuthMiddleware(jwtService, expenseHandler.Create))
-	mux.HandleFunc("GET /api/v1/expenses", middleware.AuthMiddleware(jwtService, expenseHandler.List))
-	mux.HandleFunc("GET /api/v1/expenses/{id}", middleware.AuthMiddleware(jwtService, expenseHandler.GetByID))
-	mux.HandleFunc("PUT /api/v1/expenses/{id}", middleware.AuthMiddleware(jwtService, expenseHandler.Update))
-	mux.HandleFunc("DELETE /api/v1/expenses/{id}", middleware.AuthMiddleware(jwtService, expenseHandler.Delete))
+	mux.HandleFunc("POST /api/v1/expenses", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(expenseHandler.Create))))
+	mux.HandleFunc("GET /api/v1/expenses", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, expenseHandler.List)))
+	mux.HandleFunc("GET /api/v1/expenses/summary", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, expenseHandler.Summary)))
+	mux.HandleFunc("GET /api/v1/expenses/search", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, expenseHandler.Search)))
+	mux.HandleFunc("GET /api/v1/expenses/{id}", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, expenseHandler.GetByID)))
+	mux.HandleFunc("PUT /api/v1/expenses/{id}", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(expenseHandler.Update))))
+	mux.HandleFunc("DELETE /api/v1/expenses/{id}", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(expenseHandler.Delete))))
+
+	// Rotas de categorias
+	mux.HandleFunc("GET /api/v1/categories", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, categoryHandler.List)))
+	mux.HandleFunc("POST /api/v1/categories", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(categoryHandler.Create))))
+	mux.HandleFunc("PUT /api/v1/categories/{id}", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(categoryHandler.Update))))
+	mux.HandleFunc("DELETE /api/v1/categories/{id}", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesWrite, middleware.RequireWriteAccess(categoryHandler.Delete))))
+	mux.HandleFunc("GET /api/v1/tags", middleware.AuthMiddleware(authService, middleware.RequireScope(auth.ScopeExpensesRead, tagHandler.List)))
 
 	return &expenseTestServer{
-		db:          dbpool,
-		jwtService:  jwtService,
-		authToken:   token.Token,
-		userID:      user.ID,
-		httpHandler: mux,
+		db:              dbpool,
+		jwtService:      jwtService,
+		currencyService: currencyService,
+		authToken:       token.Token,
+		userID:          user.ID,
+		httpHandler:     mux,
 	}
 }
 
@@ -100,7 +137,7 @@ func TestExpenseEndpoints(t *testing.T) {
 
 	t.Run("deve criar uma nova despesa", func(t *testing.T) {
 		input := model.CreateExpenseInput{
-			Amount:      150.50,
+			Amount:      model.MustParseMoney("150.50"),
 			Description: "Compras do mês",
 			Category:    model.CategoryGroceries,
 			Date:        time.Now().Format("2006-01-02"),
@@ -138,11 +175,12 @@ func TestExpenseEndpoints(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 
-		var response []*model.Expense
+		var response model.ExpensePage
 		err := json.NewDecoder(w.Body).Decode(&response)
 		require.NoError(t, err)
 
-		assert.Len(t, response, 1)
+		assert.Len(t, response.Items, 1)
+		assert.Nil(t, response.NextCursor)
 	})
 
 	t.Run("deve retornar erro ao tentar acessar sem autenticação", func(t *testing.T) {
@@ -161,13 +199,13 @@ func TestExpenseEndpoints(t *testing.T) {
 		w := httptest.NewRecorder()
 		server.httpHandler.ServeHTTP(w, req)
 
-		var expenses []*model.Expense
-		err := json.NewDecoder(w.Body).Decode(&expenses)
+		var page model.ExpensePage
+		err := json.NewDecoder(w.Body).Decode(&page)
 		require.NoError(t, err)
-		require.NotEmpty(t, expenses)
+		require.NotEmpty(t, page.Items)
 
-		expenseID := expenses[0].ID
-		newAmount := 175.50
+		expenseID := page.Items[0].ID
+		newAmount := model.MustParseMoney("175.50")
 		newDescription := "Compras do mês (atualizado)"
 
 		input := model.UpdateExpenseInput{
@@ -203,12 +241,12 @@ func TestExpenseEndpoints(t *testing.T) {
 		w := httptest.NewRecorder()
 		server.httpHandler.ServeHTTP(w, req)
 
-		var expenses []*model.Expense
-		err := json.NewDecoder(w.Body).Decode(&expenses)
+		var page model.ExpensePage
+		err := json.NewDecoder(w.Body).Decode(&page)
 		require.NoError(t, err)
-		require.NotEmpty(t, expenses)
+		require.NotEmpty(t, page.Items)
 
-		expenseID := expenses[0].ID
+		expenseID := page.Items[0].ID
 
 		req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/expenses/%s", expenseID), nil)
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
@@ -228,3 +266,873 @@ func TestExpenseEndpoints(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestExpenseAmounts(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+	date := time.Now().Format("2006-01-02")
+
+	t.Run("deve recusar valores com mais de duas casas decimais", func(t *testing.T) {
+		w := create(fmt.Sprintf(`{"amount": 10.005, "description": "Café", "category": "LAZER", "date": %q}`, date))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), model.ErrAmountScale.Error())
+	})
+
+	t.Run("deve recusar valores não positivos", func(t *testing.T) {
+		w := create(fmt.Sprintf(`{"amount": "0.00", "description": "Café", "category": "LAZER", "date": %q}`, date))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deve somar os valores sem erro de arredondamento", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, create(fmt.Sprintf(`{"amount": 0.1, "description": "Bala", "category": "LAZER", "date": %q}`, date)).Code)
+		require.Equal(t, http.StatusCreated, create(fmt.Sprintf(`{"amount": "0.20", "description": "Chiclete", "category": "LAZER", "date": %q}`, date)).Code)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":0.30`)
+
+		var summary model.ExpenseSummary
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&summary))
+		assert.Equal(t, model.MustParseMoney("0.30"), summary.Total)
+		assert.Equal(t, 2, summary.Count)
+		require.Len(t, summary.Categories, 1)
+		assert.Equal(t, model.CategoryLeisure, summary.Categories[0].Category)
+	})
+}
+
+func TestExpenseCurrencies(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	rates, err := currency.ParseCSV(strings.NewReader("date,currency,rate\n2024-03-01,USD,1.08\n2024-03-01,BRL,5.40\n"))
+	require.NoError(t, err)
+	_, err = server.currencyService.Import(context.Background(), rates)
+	require.NoError(t, err)
+
+	t.Run("deve converter para a moeda base com a cotação da data", func(t *testing.T) {
+		// 04/03/2024 não tem cotação; vale a de 01/03
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Currency:    "usd",
+			Description: "Jantar em Nova York",
+			Category:    model.CategoryLeisure,
+			Date:        "2024-03-04",
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, "USD", expense.Currency)
+		assert.Equal(t, "BRL", expense.BaseCurrency)
+		require.NotNil(t, expense.BaseAmount)
+		assert.Equal(t, model.MustParseMoney("50.00"), *expense.BaseAmount)
+	})
+
+	t.Run("deve usar a moeda base quando a moeda não é informada", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("5.00"),
+			Description: "Padaria",
+			Category:    model.CategoryGroceries,
+			Date:        "2024-03-04",
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, "BRL", expense.Currency)
+		require.NotNil(t, expense.BaseAmount)
+		assert.Equal(t, expense.Amount, *expense.BaseAmount)
+	})
+
+	t.Run("deve recusar moedas sem cotação", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("1000"),
+			Currency:    "JPY",
+			Description: "Lembrança",
+			Category:    model.CategoryOthers,
+			Date:        "2024-03-04",
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deve resumir na moeda base com os valores originais", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/expenses/summary", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var summary model.ExpenseSummary
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&summary))
+		assert.Equal(t, "BRL", summary.BaseCurrency)
+		assert.Equal(t, model.MustParseMoney("55.00"), summary.Total)
+		assert.False(t, summary.Incomplete)
+		require.Len(t, summary.Currencies, 2)
+		for _, total := range summary.Currencies {
+			if total.Currency == "USD" {
+				assert.Equal(t, model.MustParseMoney("10.00"), total.Amount)
+				require.NotNil(t, total.BaseAmount)
+				assert.Equal(t, model.MustParseMoney("50.00"), *total.BaseAmount)
+			}
+		}
+	})
+
+	t.Run("deve marcar o resumo como incompleto quando falta cotação", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("20.00"),
+			Currency:    "USD",
+			Description: "Livro",
+			Category:    model.CategoryOthers,
+			Date:        "2023-01-10",
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Nil(t, expense.BaseAmount)
+
+		w = request(http.MethodGet, "/api/v1/expenses/summary", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var summary model.ExpenseSummary
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&summary))
+		assert.True(t, summary.Incomplete)
+		assert.Equal(t, model.MustParseMoney("55.00"), summary.Total)
+	})
+
+	t.Run("deve trocar a moeda base do usuário", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, "/api/v1/me/currency", model.UpdateBaseCurrencyInput{BaseCurrency: "JPY"}).Code)
+
+		w := request(http.MethodPut, "/api/v1/me/currency", model.UpdateBaseCurrencyInput{BaseCurrency: "USD"})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = request(http.MethodGet, "/api/v1/expenses?start_date=2024-03-01", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page model.ExpensePage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.Len(t, page.Items, 2)
+		for _, expense := range page.Items {
+			assert.Equal(t, "USD", expense.BaseCurrency)
+			require.NotNil(t, expense.BaseAmount)
+			if expense.Currency == "BRL" {
+				// 5,00 BRL * 1,08 / 5,40 = 1,00 USD
+				assert.Equal(t, model.MustParseMoney("1.00"), *expense.BaseAmount)
+			}
+		}
+	})
+
+	t.Run("deve receber na recarga as cotações importadas por outra instância", func(t *testing.T) {
+		other := service.NewCurrencyService(currency.NewTable(), repository.NewExchangeRateRepository(server.db), repository.NewUserRepository(server.db))
+		require.NoError(t, other.Load(context.Background()))
+		assert.True(t, other.Supported("USD"))
+		assert.False(t, other.Supported("GBP"))
+
+		gbp, err := currency.ParseCSV(strings.NewReader("date,currency,rate\n2024-03-01,GBP,0.85\n"))
+		require.NoError(t, err)
+		_, err = server.currencyService.Import(context.Background(), gbp)
+		require.NoError(t, err)
+
+		require.NoError(t, other.Load(context.Background()))
+		assert.True(t, other.Supported("GBP"))
+	})
+}
+
+func TestExpenseCategories(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	createExpense := func(category model.Category) *httptest.ResponseRecorder {
+		return request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("30.00"),
+			Description: "Passagem de ônibus",
+			Category:    category,
+			Date:        "2024-03-04",
+		})
+	}
+
+	var travel model.UserCategory
+
+	t.Run("deve criar as categorias padrão para novos usuários", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/categories", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var categories []model.UserCategory
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&categories))
+		assert.Len(t, categories, 7)
+	})
+
+	t.Run("deve criar uma categoria", func(t *testing.T) {
+		color := "#1E90FF"
+		w := request(http.MethodPost, "/api/v1/categories", model.CreateCategoryInput{Name: " Transporte ", Color: &color})
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&travel))
+		assert.Equal(t, model.Category("Transporte"), travel.Name)
+		assert.Equal(t, &color, travel.Color)
+
+		w = request(http.MethodPost, "/api/v1/categories", model.CreateCategoryInput{Name: "transporte"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		invalid := "azul"
+		w = request(http.MethodPost, "/api/v1/categories", model.CreateCategoryInput{Name: "Viagens", Color: &invalid})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deve usar a categoria nas despesas", func(t *testing.T) {
+		w := createExpense("TRANSPORTE")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, travel.Name, expense.Category)
+		assert.Equal(t, travel.ID, expense.CategoryID)
+
+		w = request(http.MethodGet, "/api/v1/expenses?category=transporte", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var page model.ExpensePage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		assert.Len(t, page.Items, 1)
+
+		w = createExpense("Inexistente")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("não deve remover categorias em uso", func(t *testing.T) {
+		w := request(http.MethodDelete, "/api/v1/categories/"+travel.ID, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("deve recusar categorias arquivadas em novas despesas", func(t *testing.T) {
+		archived := true
+		w := request(http.MethodPut, "/api/v1/categories/"+travel.ID, model.UpdateCategoryInput{Archived: &archived})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = createExpense(travel.Name)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		// A categoria arquivada só aparece quando solicitada
+		w = request(http.MethodGet, "/api/v1/categories", nil)
+		var categories []model.UserCategory
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&categories))
+		assert.Len(t, categories, 7)
+
+		w = request(http.MethodGet, "/api/v1/categories?archived=true", nil)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&categories))
+		assert.Len(t, categories, 8)
+	})
+
+	t.Run("deve remover categorias sem despesas", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/categories", model.CreateCategoryInput{Name: "Viagens"})
+		require.Equal(t, http.StatusCreated, w.Code)
+		var category model.UserCategory
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&category))
+
+		w = request(http.MethodDelete, "/api/v1/categories/"+category.ID, nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = request(http.MethodDelete, "/api/v1/categories/"+category.ID, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestExpenseSubcategories(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	createCategory := func(name string, parentID *string) model.UserCategory {
+		w := request(http.MethodPost, "/api/v1/categories", model.CreateCategoryInput{Name: name, ParentID: parentID})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var category model.UserCategory
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&category))
+		return category
+	}
+
+	food := createCategory("Alimentação", nil)
+	restaurant := createCategory("Restaurante", &food.ID)
+	market := createCategory("Mercado", &food.ID)
+	delivery := createCategory("Delivery", &restaurant.ID)
+	assert.Equal(t, &food.ID, restaurant.ParentID)
+
+	for category, amount := range map[model.Category]string{
+		food.Name:       "10.00",
+		restaurant.Name: "45.00",
+		market.Name:     "120.00",
+		delivery.Name:   "30.00",
+	} {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney(amount),
+			Description: "Despesa de alimentação",
+			Category:    category,
+			Date:        "2024-03-04",
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	t.Run("deve filtrar pela categoria e todas as subcategorias", func(t *testing.T) {
+		cases := map[string]int{"Alimentação": 4, "restaurante": 2, "Delivery": 1, "Mercado": 1}
+		for name, expected := range cases {
+			w := request(http.MethodGet, "/api/v1/expenses?category="+url.QueryEscape(name), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var page model.ExpensePage
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+			assert.Len(t, page.Items, expected, name)
+		}
+	})
+
+	t.Run("deve somar as subcategorias no resumo", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/expenses/summary", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var summary model.ExpenseSummary
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&summary))
+		assert.Equal(t, model.MustParseMoney("205.00"), summary.Total)
+
+		totals := make(map[string]model.CategoryTotal)
+		for _, total := range summary.Categories {
+			totals[total.CategoryID] = total
+		}
+		assert.Equal(t, model.MustParseMoney("205.00"), totals[food.ID].Total)
+		assert.Equal(t, 4, totals[food.ID].Count)
+		assert.Equal(t, model.MustParseMoney("75.00"), totals[restaurant.ID].Total)
+		assert.Equal(t, model.MustParseMoney("30.00"), totals[delivery.ID].Total)
+		assert.Equal(t, food.ID, summary.Categories[0].CategoryID)
+	})
+
+	t.Run("não deve permitir ciclos", func(t *testing.T) {
+		for _, parentID := range []string{food.ID, delivery.ID} {
+			w := request(http.MethodPut, "/api/v1/categories/"+food.ID, model.UpdateCategoryInput{ParentID: &parentID})
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+
+		// Mover para uma categoria de outro ramo é permitido
+		w := request(http.MethodPut, "/api/v1/categories/"+delivery.ID, model.UpdateCategoryInput{ParentID: &market.ID})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		root := ""
+		w = request(http.MethodPut, "/api/v1/categories/"+delivery.ID, model.UpdateCategoryInput{ParentID: &root})
+		require.Equal(t, http.StatusOK, w.Code)
+		var category model.UserCategory
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&category))
+		assert.Nil(t, category.ParentID)
+	})
+
+	t.Run("deve recusar categoria pai inexistente", func(t *testing.T) {
+		missing := "00000000-0000-0000-0000-000000000000"
+		w := request(http.MethodPost, "/api/v1/categories", model.CreateCategoryInput{Name: "Padaria", ParentID: &missing})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("não deve remover categorias com subcategorias", func(t *testing.T) {
+		empty := createCategory("Lanches", nil)
+		createCategory("Cafeteria", &empty.ID)
+
+		w := request(http.MethodDelete, "/api/v1/categories/"+empty.ID, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestExpenseTags(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	createExpense := func(description string, tags []string) model.Expense {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("20.00"),
+			Description: description,
+			Category:    model.CategoryOthers,
+			Tags:        tags,
+			Date:        "2024-03-04",
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		return expense
+	}
+
+	listDescriptions := func(query string) []string {
+		w := request(http.MethodGet, "/api/v1/expenses?"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page model.ExpensePage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		descriptions := []string{}
+		for _, expense := range page.Items {
+			descriptions = append(descriptions, expense.Description)
+		}
+		return descriptions
+	}
+
+	hotel := createExpense("Hotel", []string{"Viagem-2026", " reembolsável ", "viagem-2026"})
+	createExpense("Passagem", []string{"viagem-2026"})
+	createExpense("Almoço com cliente", []string{"reembolsável"})
+	untagged := createExpense("Padaria", nil)
+
+	t.Run("deve normalizar e retornar as tags", func(t *testing.T) {
+		assert.Equal(t, []string{"reembolsável", "viagem-2026"}, hotel.Tags)
+		assert.Equal(t, []string{}, untagged.Tags)
+
+		w := request(http.MethodGet, "/api/v1/expenses/"+hotel.ID, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, hotel.Tags, expense.Tags)
+	})
+
+	t.Run("deve filtrar por qualquer uma ou todas as tags", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Hotel", "Passagem"}, listDescriptions("tag=viagem-2026"))
+		assert.ElementsMatch(t, []string{"Hotel", "Passagem", "Almoço com cliente"}, listDescriptions("tag=Viagem-2026&tag=reembols%C3%A1vel"))
+		assert.ElementsMatch(t, []string{"Hotel"}, listDescriptions("tag=viagem-2026&tag=reembols%C3%A1vel&tag_match=all"))
+		assert.Empty(t, listDescriptions("tag=inexistente"))
+	})
+
+	t.Run("deve substituir as tags na atualização", func(t *testing.T) {
+		tags := []string{"pessoal"}
+		w := request(http.MethodPut, "/api/v1/expenses/"+hotel.ID, model.UpdateExpenseInput{Tags: &tags})
+		require.Equal(t, http.StatusOK, w.Code)
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, []string{"pessoal"}, expense.Tags)
+
+		// Sem o campo tags, as tags atuais são mantidas
+		description := "Hotel em Lisboa"
+		w = request(http.MethodPut, "/api/v1/expenses/"+hotel.ID, model.UpdateExpenseInput{Description: &description})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, []string{"pessoal"}, expense.Tags)
+	})
+
+	t.Run("deve recusar tags inválidas", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("1.00"),
+			Description: "Café",
+			Category:    model.CategoryOthers,
+			Tags:        []string{"  "},
+			Date:        "2024-03-04",
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deve listar as tags com a quantidade de despesas", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/tags", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var tags []model.TagUsage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tags))
+		assert.Equal(t, []model.TagUsage{
+			{Name: "pessoal", Count: 1},
+			{Name: "reembolsável", Count: 1},
+			{Name: "viagem-2026", Count: 1},
+		}, tags)
+	})
+}
+
+func TestExpensePagination(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Sete despesas, com datas e valores repetidos para exercitar o desempate pelo ID
+	for i := 0; i < 7; i++ {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney(fmt.Sprintf("%d.00", 10+i%3)),
+			Description: fmt.Sprintf("Despesa %d", i),
+			Category:    model.CategoryOthers,
+			Date:        fmt.Sprintf("2024-03-%02d", 1+i%2),
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	// readAll percorre todas as páginas e retorna as despesas na ordem recebida
+	readAll := func(query string) []*model.Expense {
+		var all []*model.Expense
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			path := "/api/v1/expenses?limit=3&" + query
+			if cursor != "" {
+				path += "&cursor=" + url.QueryEscape(cursor)
+			}
+			w := request(http.MethodGet, path, nil)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var page model.ExpensePage
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+			assert.LessOrEqual(t, len(page.Items), 3)
+			all = append(all, page.Items...)
+			if page.NextCursor == nil {
+				return all
+			}
+			cursor = *page.NextCursor
+		}
+		t.Fatal("paginação não terminou")
+		return nil
+	}
+
+	t.Run("deve percorrer todas as despesas sem repetir nem pular", func(t *testing.T) {
+		for _, query := range []string{"", "sort=amount&order=asc", "sort=amount&order=desc", "sort=created_at", "sort=description&order=asc"} {
+			expenses := readAll(query)
+			require.Len(t, expenses, 7, query)
+
+			seen := make(map[string]bool)
+			for _, expense := range expenses {
+				assert.False(t, seen[expense.ID], query)
+				seen[expense.ID] = true
+			}
+		}
+	})
+
+	t.Run("deve ordenar pelo campo e direção pedidos", func(t *testing.T) {
+		expenses := readAll("sort=amount&order=asc")
+		for i := 1; i < len(expenses); i++ {
+			assert.LessOrEqual(t, expenses[i-1].Amount, expenses[i].Amount)
+		}
+
+		expenses = readAll("")
+		for i := 1; i < len(expenses); i++ {
+			assert.False(t, expenses[i-1].Date.Before(expenses[i].Date))
+		}
+	})
+
+	t.Run("deve recusar parâmetros inválidos", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/expenses?limit=2", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var page model.ExpensePage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		require.NotNil(t, page.NextCursor)
+
+		for _, query := range []string{
+			"limit=0",
+			"limit=abc",
+			"limit=500",
+			"sort=user_id",
+			"order=sideways",
+			"cursor=invalido",
+			"sort=amount&cursor=" + url.QueryEscape(*page.NextCursor),
+		} {
+			w := request(http.MethodGet, "/api/v1/expenses?"+query, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
+func TestExpenseFilters(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	create := func(description string, amount string, category model.Category) model.Expense {
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney(amount),
+			Description: description,
+			Category:    category,
+			Date:        "2024-03-04",
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		return expense
+	}
+
+	list := func(query string) []string {
+		w := request(http.MethodGet, "/api/v1/expenses?sort=description&order=asc&"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var page model.ExpensePage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		descriptions := []string{}
+		for _, expense := range page.Items {
+			descriptions = append(descriptions, expense.Description)
+		}
+		return descriptions
+	}
+
+	create("Supermercado do bairro", "80.00", model.CategoryGroceries)
+	create("Cinema", "35.50", model.CategoryLeisure)
+	create("Fone de ouvido", "199.90", model.CategoryElectronics)
+	create("Desconto 100% no mercado", "10.00", model.CategoryOthers)
+
+	t.Run("deve filtrar por faixa de valor", func(t *testing.T) {
+		assert.Equal(t, []string{"Cinema", "Supermercado do bairro"}, list("min_amount=35.50&max_amount=80"))
+		assert.Equal(t, []string{"Fone de ouvido"}, list("min_amount=100"))
+	})
+
+	t.Run("deve buscar na descrição sem diferenciar maiúsculas", func(t *testing.T) {
+		assert.Equal(t, []string{"Desconto 100% no mercado", "Supermercado do bairro"}, list("q=MERCADO"))
+		// Curingas do LIKE são buscados literalmente
+		assert.Equal(t, []string{"Desconto 100% no mercado"}, list("q="+url.QueryEscape("100%")))
+		assert.Equal(t, []string{}, list("q=_"))
+	})
+
+	t.Run("deve aceitar várias categorias", func(t *testing.T) {
+		assert.Equal(t, []string{"Cinema", "Fone de ouvido"}, list("category=lazer&category=ELETRONICA"))
+	})
+
+	t.Run("deve filtrar por criação e alteração", func(t *testing.T) {
+		before := time.Now().UTC().Add(-time.Minute).Format(time.RFC3339)
+		after := time.Now().UTC().Add(time.Minute).Format(time.RFC3339)
+		assert.Len(t, list("created_after="+url.QueryEscape(before)), 4)
+		assert.Empty(t, list("created_after="+url.QueryEscape(after)))
+		assert.Empty(t, list("updated_after="+url.QueryEscape(after)))
+	})
+
+	t.Run("deve combinar o período predefinido com os demais filtros", func(t *testing.T) {
+		today := time.Now().Format("2006-01-02")
+		w := request(http.MethodPost, "/api/v1/expenses", model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("60.00"),
+			Description: "Show",
+			Category:    model.CategoryLeisure,
+			Date:        today,
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		assert.Equal(t, []string{"Show"}, list("period=week"))
+		assert.Equal(t, []string{"Show"}, list("period=week&category=lazer"))
+		assert.Empty(t, list("period=week&category=mantimentos"))
+		assert.Empty(t, list("period=quarter&end_date=2024-03-04"))
+		assert.Equal(t, []string{"Show"}, list("period=quarter&start_date="+today))
+
+		w = request(http.MethodGet, "/api/v1/expenses?period=year", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response struct {
+			Fields map[string]string `json:"fields"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Contains(t, response.Fields, "period")
+	})
+
+	t.Run("deve responder 400 com o campo inválido", func(t *testing.T) {
+		for query, field := range map[string]string{
+			"start_date=01/03/2024":          "start_date",
+			"end_date=2024-13-01":            "end_date",
+			"min_amount=abc":                 "min_amount",
+			"max_amount=1.999":               "max_amount",
+			"min_amount=-1":                  "min_amount",
+			"min_amount=50&max_amount=10":    "max_amount",
+			"created_after=ontem":            "created_after",
+			"updated_after=2024-03-01T10:00": "updated_after",
+			"tag_match=some":                 "tag_match",
+		} {
+			for _, path := range []string{"/api/v1/expenses?", "/api/v1/expenses/summary?"} {
+				w := request(http.MethodGet, path+query, nil)
+				require.Equal(t, http.StatusBadRequest, w.Code, path+query)
+
+				var response struct {
+					Fields map[string]string `json:"fields"`
+				}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Contains(t, response.Fields, field, path+query)
+			}
+		}
+	})
+}
+
+func TestExpenseSearch(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", server.authToken))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, input := range []model.CreateExpenseInput{
+		{Description: "Remédios da farmácia", Merchant: "Drogaria Central", Category: model.CategoryHealth},
+		{Description: "Almoço", Merchant: "Restaurante <Sabor & Cia>", Notes: "Almoço com clientes da farmácia", Category: model.CategoryLeisure},
+		{Description: "Cabo USB", Merchant: "Loja de eletrônicos", Category: model.CategoryElectronics},
+	} {
+		input.Amount = model.MustParseMoney("20.00")
+		input.Date = "2024-03-04"
+		w := request(http.MethodPost, "/api/v1/expenses", input)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var expense model.Expense
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&expense))
+		assert.Equal(t, input.Merchant, expense.Merchant)
+		assert.Equal(t, input.Notes, expense.Notes)
+	}
+
+	search := func(query string) model.ExpenseSearchResults {
+		w := request(http.MethodGet, "/api/v1/expenses/search?"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var results model.ExpenseSearchResults
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&results))
+		return results
+	}
+
+	t.Run("deve encontrar sem acentos e ordenar por relevância", func(t *testing.T) {
+		results := search("q=farmacia")
+		require.Len(t, results.Items, 2)
+
+		// A descrição pesa mais que as observações
+		assert.Equal(t, "Remédios da farmácia", results.Items[0].Description)
+		assert.Contains(t, results.Items[0].Highlights.Description, "<mark>farmácia</mark>")
+		assert.Empty(t, results.Items[0].Highlights.Notes)
+		assert.Equal(t, "Almoço", results.Items[1].Description)
+		assert.Contains(t, results.Items[1].Highlights.Notes, "<mark>farmácia</mark>")
+		assert.GreaterOrEqual(t, results.Items[0].Rank, results.Items[1].Rank)
+	})
+
+	t.Run("deve considerar variações das palavras", func(t *testing.T) {
+		results := search("q=" + url.QueryEscape("eletrônico"))
+		require.Len(t, results.Items, 1)
+		assert.Contains(t, results.Items[0].Highlights.Merchant, "<mark>eletrônicos</mark>")
+	})
+
+	t.Run("deve escapar o texto dos trechos", func(t *testing.T) {
+		results := search("q=sabor")
+		require.Len(t, results.Items, 1)
+		merchant := results.Items[0].Highlights.Merchant
+		assert.Contains(t, merchant, "&lt;<mark>Sabor</mark>")
+		assert.Contains(t, merchant, "&amp;")
+		assert.NotContains(t, merchant, "<Sabor")
+	})
+
+	t.Run("deve aplicar os filtros da listagem", func(t *testing.T) {
+		results := search("q=farmacia&category=lazer")
+		require.Len(t, results.Items, 1)
+		assert.Equal(t, "Almoço", results.Items[0].Description)
+
+		assert.Empty(t, search("q=inexistente").Items)
+	})
+
+	t.Run("deve exigir o texto da busca", func(t *testing.T) {
+		for _, query := range []string{"", "q=", "q=farmacia&limit=0", "q=farmacia&min_amount=abc"} {
+			w := request(http.MethodGet, "/api/v1/expenses/search?"+query, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+	"expenseapi/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFA(t *testing.T) {
+	require.NoError(t, cleanDatabase())
+
+	srv := setupTestServer(t, testDB)
+	ctx := context.Background()
+
+	credentials := model.LoginInput{Email: "mfa@example.com", Password: "password123"}
+	_, err := srv.authService.Register(ctx, model.CreateUserInput{Email: credentials.Email, Password: credentials.Password})
+	require.NoError(t, err)
+	login, err := srv.authService.Login(ctx, credentials)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/2fa/verify", srv.mfaHandler.Verify)
+	mux.HandleFunc("POST /api/v1/me/2fa/setup", middleware.AuthMiddleware(srv.authService, srv.mfaHandler.Setup))
+	mux.HandleFunc("POST /api/v1/me/2fa/confirm", middleware.AuthMiddleware(srv.authService, srv.mfaHandler.Confirm))
+
+	request := func(path, token string, input interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// Configuração
+	w := request("/api/v1/me/2fa/setup", login.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var setup model.MFASetupResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&setup))
+	assert.Contains(t, setup.OTPAuthURI, "otpauth://totp/")
+
+	// Confirmação com código incorreto e depois com o código atual
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/me/2fa/confirm", login.Token, model.MFAConfirmInput{Code: "000000"}).Code)
+
+	code, err := auth.TOTPCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+	w = request("/api/v1/me/2fa/confirm", login.Token, model.MFAConfirmInput{Code: code})
+	require.Equal(t, http.StatusOK, w.Code)
+	var confirm model.MFAConfirmResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&confirm))
+	require.Len(t, confirm.RecoveryCodes, 10)
+
+	// A senha agora só libera a segunda etapa
+	pending, err := srv.authService.Login(ctx, credentials)
+	require.NoError(t, err)
+	assert.True(t, pending.MFARequired)
+	assert.Empty(t, pending.Token)
+
+	// O código já usado na confirmação não pode ser reutilizado
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/auth/2fa/verify", "", model.MFAVerifyInput{MFAToken: pending.MFAToken, Code: code}).Code)
+
+	// Um código de recuperação conclui o login, mas apenas uma vez
+	w = request("/api/v1/auth/2fa/verify", "", model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: confirm.RecoveryCodes[0]})
+	require.Equal(t, http.StatusOK, w.Code)
+	var tokens model.LoginResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&tokens))
+	assert.NotEmpty(t, tokens.Token)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/auth/2fa/verify", "", model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: confirm.RecoveryCodes[0]}).Code)
+
+	// O token intermediário não vale como token de acesso
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/me/2fa/setup", pending.MFAToken, nil).Code)
+
+	// Depois de cinco códigos errados o token intermediário é invalidado, mesmo
+	// que o bloqueio da conta permita mais tentativas
+	lenient := auth.NewLoginLimiter(repository.NewLoginAttemptRepository(testDB),
+		auth.LimitPolicy{Threshold: 100, BaseDelay: time.Minute, MaxDelay: time.Minute, Window: time.Hour},
+		auth.LimitPolicy{Threshold: 100, BaseDelay: time.Minute, MaxDelay: time.Minute, Window: time.Hour},
+	)
+	jwtService := auth.NewJWTService("test_secret_key", 24*time.Hour, 7*24*time.Hour)
+	mfaService := service.NewMFAService(repository.NewUserRepository(testDB), repository.NewMFARepository(testDB), jwtService, srv.authService, lenient, "Expense API")
+
+	pending, err = srv.authService.Login(ctx, credentials)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err = mfaService.Verify(ctx, model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: "00000-00000"}, model.ClientInfo{})
+		require.ErrorIs(t, err, service.ErrInvalidMFACode)
+	}
+	_, err = mfaService.Verify(ctx, model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: confirm.RecoveryCodes[1]}, model.ClientInfo{})
+	assert.ErrorIs(t, err, service.ErrInvalidMFAToken)
+
+	// O código de recuperação não foi consumido e vale com um novo token
+	pending, err = srv.authService.Login(ctx, credentials)
+	require.NoError(t, err)
+	_, err = mfaService.Verify(ctx, model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: confirm.RecoveryCodes[1]}, model.ClientInfo{})
+	assert.NoError(t, err)
+
+	// As falhas contam para o bloqueio da conta, como as senhas erradas
+	pending, err = srv.authService.Login(ctx, credentials)
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, http.StatusUnauthorized, request("/api/v1/auth/2fa/verify", "", model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: "00000-00000"}).Code)
+	}
+	w = request("/api/v1/auth/2fa/verify", "", model.MFAVerifyInput{MFAToken: pending.MFAToken, RecoveryCode: confirm.RecoveryCodes[2]})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// O bloqueio vale para o login com o email escrito de outra forma
+	_, err = srv.authService.Login(ctx, model.LoginInput{Email: " MFA@Example.com ", Password: credentials.Password})
+	assert.ErrorIs(t, err, service.ErrTooManyAttempts)
+}
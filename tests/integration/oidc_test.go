@@ -0,0 +1,222 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"expenseapi/internal/config"
+	"expenseapi/internal/handler"
+	"expenseapi/internal/model"
+	"expenseapi/internal/oidc"
+	"expenseapi/internal/repository"
+	"expenseapi/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIssuer é um provedor OIDC mínimo: descoberta, JWKS e endpoint de token
+// com verificação do PKCE
+type mockIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newMockIssuer(t *testing.T, clientID string) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer := &mockIssuer{key: key, clientID: clientID, codes: make(map[string]mockAuthorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "mock-key",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("POST /token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// authorize simula o usuário aprovando o login no provedor e retorna o código
+func (m *mockIssuer) authorize(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Equal(t, m.clientID, query.Get("client_id"))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	code := "code-" + query.Get("state")
+	m.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce"), claims: claims}
+	return code
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	authorization, ok := m.codes[r.FormValue("code")]
+	delete(m.codes, r.FormValue("code"))
+	m.mu.Unlock()
+
+	if !ok || oidc.CodeChallenge(r.FormValue("code_verifier")) != authorization.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   m.server.URL,
+		"aud":   m.clientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": authorization.nonce,
+	}
+	for k, v := range authorization.claims {
+		claims[k] = v
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = "mock-key"
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "mock", "token_type": "Bearer", "id_token": signed})
+}
+
+func TestOIDCLogin(t *testing.T) {
+	require.NoError(t, cleanDatabase())
+
+	srv := setupTestServer(t, testDB)
+	issuer := newMockIssuer(t, "expense-api")
+	provider := oidc.NewProvider(config.OIDCProviderConfig{
+		Name:        "corp",
+		Issuer:      issuer.server.URL,
+		ClientID:    "expense-api",
+		RedirectURL: "http://localhost:8081/api/v1/auth/oidc/corp/callback",
+		Scopes:      []string{"openid", "email"},
+	}, nil)
+	oidcService := service.NewOIDCService([]*oidc.Provider{provider}, repository.NewOIDCRepository(testDB), repository.NewUserRepository(testDB), srv.authService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/auth/oidc/{provider}/start", oidcHandler.Start)
+	mux.HandleFunc("GET /api/v1/auth/oidc/{provider}/callback", oidcHandler.Callback)
+
+	// login percorre o fluxo completo e retorna a resposta do callback
+	login := func(t *testing.T, claims jwt.MapClaims) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/corp/start", nil))
+		require.Equal(t, http.StatusFound, w.Code)
+
+		cookies := w.Result().Cookies()
+		require.NotEmpty(t, cookies)
+		location := w.Header().Get("Location")
+		state, _ := url.Parse(location)
+		code := issuer.authorize(t, location, claims)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/corp/callback?"+url.Values{
+			"code":  {code},
+			"state": {state.Query().Get("state")},
+		}.Encode(), nil)
+		req.AddCookie(cookies[0])
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("deve criar o usuário no primeiro login", func(t *testing.T) {
+		w := login(t, jwt.MapClaims{"sub": "user-1", "email": "sso@example.com", "email_verified": true})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var tokens model.LoginResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tokens))
+		assert.NotEmpty(t, tokens.Token)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		user, err := repository.NewUserRepository(testDB).FindByEmail(context.Background(), "sso@example.com")
+		require.NoError(t, err)
+		assert.True(t, user.IsEmailVerified())
+	})
+
+	t.Run("deve vincular uma conta existente com email verificado", func(t *testing.T) {
+		user, err := srv.authService.Register(context.Background(), model.CreateUserInput{Email: "local@example.com", Password: "password123"})
+		require.NoError(t, err)
+
+		// Conta local sem email verificado não é vinculada
+		w := login(t, jwt.MapClaims{"sub": "user-2", "email": "local@example.com", "email_verified": true})
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		// O provedor pode informar o email com outras maiúsculas
+		require.NoError(t, repository.NewUserRepository(testDB).MarkEmailVerified(context.Background(), user.ID))
+		w = login(t, jwt.MapClaims{"sub": "user-2", "email": "Local@Example.COM", "email_verified": true})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		identity, err := repository.NewOIDCRepository(testDB).FindIdentity(context.Background(), "corp", "user-2")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, identity.UserID)
+	})
+
+	t.Run("deve recusar email não verificado pelo provedor", func(t *testing.T) {
+		w := login(t, jwt.MapClaims{"sub": "user-3", "email": "unverified@example.com", "email_verified": false})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("deve recusar callback sem o cookie de state", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/corp/callback?code=x&state=y", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deve retornar 404 para provedor desconhecido", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/outro/start", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalTokens(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var encoded []byte
+		if body != nil {
+			var err error
+			encoded, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPost, "/api/v1/me/tokens", server.authToken, model.CreatePersonalTokenInput{
+		Name:   "importador",
+		Scopes: []string{auth.ScopeExpensesRead},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created model.CreatePersonalTokenResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	require.NotEmpty(t, created.Token)
+
+	t.Run("deve autenticar com o token pessoal respeitando os escopos", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/expenses", created.Token, nil).Code)
+		assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/api/v1/expenses", created.Token, model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Description: "Teste",
+			Category:    model.CategoryOthers,
+			Date:        time.Now().Format("2006-01-02"),
+		}).Code)
+	})
+
+	t.Run("não deve permitir gerenciar a conta com token pessoal", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api/v1/me/tokens", created.Token, nil).Code)
+	})
+
+	t.Run("deve listar os tokens sem expor o valor", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/me/tokens", server.authToken, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), created.Token)
+
+		var tokens []model.PersonalToken
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tokens))
+		require.Len(t, tokens, 1)
+		assert.NotNil(t, tokens[0].LastUsedAt)
+	})
+
+	t.Run("deve rejeitar o token após a revogação", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/api/v1/me/tokens/"+created.ID, server.authToken, nil).Code)
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/expenses", created.Token, nil).Code)
+	})
+
+	t.Run("deve revogar os tokens pessoais no logout geral", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/me/tokens", server.authToken, model.CreatePersonalTokenInput{
+			Name:   "backup",
+			Scopes: []string{auth.ScopeExpensesRead},
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+		var backup model.CreatePersonalTokenResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&backup))
+		require.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/expenses", backup.Token, nil).Code)
+
+		require.Equal(t, http.StatusNoContent, request(http.MethodPost, "/api/v1/auth/logout-all", server.authToken, nil).Code)
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/expenses", backup.Token, nil).Code)
+	})
+}
+
+func TestPersonalTokensReadOnly(t *testing.T) {
+	server := setupExpenseTestServer(t)
+	defer server.db.Close()
+
+	request := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var encoded []byte
+		if body != nil {
+			var err error
+			encoded, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		server.httpHandler.ServeHTTP(w, req)
+		return w
+	}
+	createExpense := func(token string) int {
+		return request(http.MethodPost, "/api/v1/expenses", token, model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Description: "Teste",
+			Category:    model.CategoryOthers,
+			Date:        time.Now().Format("2006-01-02"),
+		}).Code
+	}
+
+	// Token de escrita criado enquanto a conta ainda podia escrever
+	w := request(http.MethodPost, "/api/v1/me/tokens", server.authToken, model.CreatePersonalTokenInput{
+		Name:   "importador",
+		Scopes: []string{auth.ScopeExpensesRead, auth.ScopeExpensesWrite},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var writer model.CreatePersonalTokenResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&writer))
+	require.Equal(t, http.StatusCreated, createExpense(writer.Token))
+
+	_, err := server.db.Exec(context.Background(), "UPDATE users SET role = 'read_only' WHERE id = $1", server.userID)
+	require.NoError(t, err)
+
+	w = request(http.MethodPost, "/api/v1/auth/login", "", model.LoginInput{Email: "test@example.com", Password: "password123"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var login model.LoginResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&login))
+
+	t.Run("deve aplicar a restrição de leitura atual do dono", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, createExpense(writer.Token))
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/expenses", writer.Token, nil).Code)
+	})
+
+	t.Run("não deve criar tokens de escrita para contas somente leitura", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/me/tokens", login.Token, model.CreatePersonalTokenInput{
+			Name:   "escrita",
+			Scopes: []string{auth.ScopeExpensesWrite},
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = request(http.MethodPost, "/api/v1/me/tokens", login.Token, model.CreatePersonalTokenInput{
+			Name:   "leitura",
+			Scopes: []string{auth.ScopeExpensesRead},
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
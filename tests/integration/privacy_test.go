@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"expenseapi/internal/handler"
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"expenseapi/internal/repository"
+	"expenseapi/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountPrivacy(t *testing.T) {
+	require.NoError(t, cleanDatabase())
+
+	srv := setupTestServer(t, testDB)
+	ctx := context.Background()
+	userRepo := repository.NewUserRepository(testDB)
+	expenseRepo := repository.NewExpenseRepository(testDB)
+
+	credentials := model.LoginInput{Email: "privacy@example.com", Password: "password123"}
+	user, err := srv.authService.Register(ctx, model.CreateUserInput{Email: credentials.Email, Password: credentials.Password})
+	require.NoError(t, err)
+	login, err := srv.authService.Login(ctx, credentials)
+	require.NoError(t, err)
+
+	// Toda conta nova recebe as categorias padrão
+	category, err := repository.NewCategoryRepository(testDB).FindByName(ctx, user.ID, string(model.CategoryOthers))
+	require.NoError(t, err)
+
+	for _, description := range []string{"Supermercado", "Cinema, pipoca"} {
+		require.NoError(t, expenseRepo.Create(ctx, &model.Expense{
+			UserID:      user.ID,
+			Amount:      model.MustParseMoney("42.5"),
+			Currency:    "BRL",
+			Description: description,
+			Category:    category.Name,
+			CategoryID:  category.ID,
+			Date:        time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		}))
+	}
+
+	// Textos que uma planilha executaria como fórmula
+	require.NoError(t, expenseRepo.Create(ctx, &model.Expense{
+		UserID:      user.ID,
+		Amount:      model.MustParseMoney("1"),
+		Currency:    "BRL",
+		Description: "=HYPERLINK(\"http://example.com\")",
+		Merchant:    "+Loja",
+		Notes:       "-nota",
+		Tags:        []string{"@tag"},
+		Category:    category.Name,
+		CategoryID:  category.ID,
+		Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}))
+
+	// Sem prazo de carência, a exclusão agendada já pode ser executada
+	privacyService := service.NewPrivacyService(userRepo, expenseRepo, srv.authService, 0)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/me/export", middleware.AuthMiddleware(srv.authService, privacyHandler.Export))
+	mux.HandleFunc("DELETE /api/v1/me", middleware.AuthMiddleware(srv.authService, privacyHandler.Delete))
+
+	request := func(method, path, token string, input interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("exporta_perfil_e_despesas", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/me/export", login.Token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		require.NoError(t, err)
+
+		files := make(map[string][]byte)
+		for _, file := range archive.File {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			reader.Close()
+			files[file.Name] = content
+		}
+		require.Contains(t, files, "profile.json")
+		require.Contains(t, files, "expenses.json")
+		require.Contains(t, files, "expenses.csv")
+
+		var profile struct {
+			User model.User `json:"user"`
+		}
+		require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+		assert.Equal(t, credentials.Email, profile.User.Email)
+
+		var expenses []model.Expense
+		require.NoError(t, json.Unmarshal(files["expenses.json"], &expenses))
+		assert.Len(t, expenses, 3)
+
+		records, err := csv.NewReader(bytes.NewReader(files["expenses.csv"])).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, "amount", records[0][3])
+		assert.Equal(t, "42.50", records[1][3])
+
+		var formula []string
+		for _, record := range records[1:] {
+			if record[3] == "1.00" {
+				formula = record
+			}
+		}
+		require.NotNil(t, formula)
+		assert.Equal(t, []string{"'=HYPERLINK(\"http://example.com\")", "'+Loja", "'-nota", "'@tag"}, formula[5:9])
+	})
+
+	t.Run("exclusao_exige_a_senha", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodDelete, "/api/v1/me", login.Token, model.DeleteAccountInput{}).Code)
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodDelete, "/api/v1/me", login.Token, model.DeleteAccountInput{Password: "senha-errada"}).Code)
+
+		stored, err := userRepo.FindByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.DeletionScheduledAt)
+	})
+
+	t.Run("novo_login_cancela_a_exclusao", func(t *testing.T) {
+		w := request(http.MethodDelete, "/api/v1/me", login.Token, model.DeleteAccountInput{Password: credentials.Password})
+		require.Equal(t, http.StatusAccepted, w.Code)
+
+		// As sessões são encerradas no pedido
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/me/export", login.Token, nil).Code)
+
+		login, err = srv.authService.Login(ctx, credentials)
+		require.NoError(t, err)
+
+		stored, err := userRepo.FindByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.DeletionScheduledAt)
+	})
+
+	t.Run("exclusao_definitiva_apos_o_prazo", func(t *testing.T) {
+		w := request(http.MethodDelete, "/api/v1/me", login.Token, model.DeleteAccountInput{Password: credentials.Password})
+		require.Equal(t, http.StatusAccepted, w.Code)
+
+		deleted, err := privacyService.PurgeDeleted(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, err = userRepo.FindByID(ctx, user.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		expenses, err := expenseRepo.List(ctx, user.ID, nil)
+		require.NoError(t, err)
+		assert.Empty(t, expenses)
+	})
+}
@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"expenseapi/internal/auth"
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	require.NoError(t, cleanDatabase())
+
+	srv := setupTestServer(t, testDB)
+	ctx := context.Background()
+
+	credentials := model.LoginInput{Email: "sessions@example.com", Password: "password123"}
+	_, err := srv.authService.Register(ctx, model.CreateUserInput{Email: credentials.Email, Password: credentials.Password})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/auth/login", srv.authHandler.Login)
+	mux.HandleFunc("POST /api/v1/auth/refresh", srv.authHandler.Refresh)
+	mux.HandleFunc("GET /api/v1/me/sessions", middleware.AuthMiddleware(srv.authService, middleware.RequireSession(srv.sessionHandler.List)))
+	mux.HandleFunc("DELETE /api/v1/me/sessions/{id}", middleware.AuthMiddleware(srv.authService, middleware.RequireSession(srv.sessionHandler.Delete)))
+
+	request := func(method, path, token string, input interface{}, userAgent string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.RemoteAddr = "203.0.113.10:4321"
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	login := func(userAgent string) model.LoginResponse {
+		w := request(http.MethodPost, "/api/v1/auth/login", "", credentials, userAgent)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response model.LoginResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		return response
+	}
+
+	listSessions := func(token string) []model.Session {
+		w := request(http.MethodGet, "/api/v1/me/sessions", token, nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var sessions []model.Session
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&sessions))
+		return sessions
+	}
+
+	laptop := login("Firefox/120.0")
+	phone := login("Mobile Safari/17.0")
+
+	// Cada login registra uma sessão com o dispositivo e o IP de origem
+	sessions := listSessions(laptop.Token)
+	require.Len(t, sessions, 2)
+
+	var current, other model.Session
+	for _, session := range sessions {
+		assert.Equal(t, "203.0.113.10", session.IP)
+		if session.Current {
+			current = session
+		} else {
+			other = session
+		}
+	}
+	assert.Equal(t, "Firefox/120.0", current.UserAgent)
+	assert.Equal(t, "Mobile Safari/17.0", other.UserAgent)
+
+	t.Run("renovacao_mantem_a_sessao", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/auth/refresh", "", model.RefreshTokenInput{RefreshToken: phone.RefreshToken}, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&phone))
+
+		assert.Len(t, listSessions(laptop.Token), 2)
+	})
+
+	t.Run("sessao_de_outro_usuario_nao_e_encontrada", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/api/v1/me/sessions/nao-e-um-uuid", laptop.Token, nil, "").Code)
+
+		otherCredentials := model.CreateUserInput{Email: "outro@example.com", Password: "password123"}
+		_, err := srv.authService.Register(ctx, otherCredentials)
+		require.NoError(t, err)
+		otherLogin, err := srv.authService.Login(ctx, model.LoginInput{Email: otherCredentials.Email, Password: otherCredentials.Password})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/api/v1/me/sessions/"+other.ID, otherLogin.Token, nil, "").Code)
+	})
+
+	t.Run("encerrar_sessao_remotamente", func(t *testing.T) {
+		w := request(http.MethodDelete, "/api/v1/me/sessions/"+other.ID, laptop.Token, nil, "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		// O token de acesso e o refresh token da sessão encerrada deixam de valer
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/me/sessions", phone.Token, nil, "").Code)
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/api/v1/auth/refresh", "", model.RefreshTokenInput{RefreshToken: phone.RefreshToken}, "").Code)
+
+		// As demais sessões continuam ativas
+		sessions := listSessions(laptop.Token)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, current.ID, sessions[0].ID)
+
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/api/v1/me/sessions/"+other.ID, laptop.Token, nil, "").Code)
+	})
+
+	t.Run("sessao_com_refresh_token_expirado_nao_e_listada", func(t *testing.T) {
+		tablet := login("Tablet/1.0")
+		require.Len(t, listSessions(laptop.Token), 2)
+
+		_, err := srv.db.Exec(ctx,
+			`UPDATE refresh_tokens SET expires_at = $1 WHERE token_hash = $2`,
+			time.Now().Add(-time.Minute), auth.HashToken(tablet.RefreshToken))
+		require.NoError(t, err)
+
+		sessions := listSessions(laptop.Token)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, current.ID, sessions[0].ID)
+	})
+}
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"expenseapi/internal/middleware"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("erro ao interpretar proxies confiáveis: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		trusted    bool
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{
+			name:       "sem_proxies_informados_vale_o_ultimo_endereco",
+			remoteAddr: "10.0.0.1:4321",
+			forwarded:  []string{"1.1.1.1, 2.2.2.2"},
+			expected:   "2.2.2.2",
+		},
+		{
+			name:       "pula_os_proxies_confiaveis_da_direita",
+			trusted:    true,
+			remoteAddr: "10.0.0.1:4321",
+			forwarded:  []string{"6.6.6.6, 2.2.2.2, 10.1.2.3, 192.168.1.1"},
+			expected:   "2.2.2.2",
+		},
+		{
+			name:       "headers_repetidos_sao_concatenados",
+			trusted:    true,
+			remoteAddr: "10.0.0.1:4321",
+			forwarded:  []string{"6.6.6.6", "2.2.2.2, 10.1.2.3"},
+			expected:   "2.2.2.2",
+		},
+		{
+			name:       "ignora_o_header_de_quem_nao_e_proxy",
+			trusted:    true,
+			remoteAddr: "3.3.3.3:4321",
+			forwarded:  []string{"2.2.2.2"},
+			expected:   "3.3.3.3",
+		},
+		{
+			name:       "para_no_endereco_invalido",
+			trusted:    true,
+			remoteAddr: "10.0.0.1:4321",
+			forwarded:  []string{"2.2.2.2, forjado, 10.1.2.3"},
+			expected:   "10.1.2.3",
+		},
+		{
+			name:       "todos_proxies_vale_o_mais_distante",
+			trusted:    true,
+			remoteAddr: "10.0.0.1:4321",
+			forwarded:  []string{"10.9.9.9, 10.1.2.3"},
+			expected:   "10.9.9.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies := trusted
+			if !tt.trusted {
+				proxies = nil
+			}
+
+			var got string
+			handler := middleware.RealIP(proxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = middleware.ClientIP(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.expected {
+				t.Errorf("IP esperado %s, obtido %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("proxy_invalido", func(t *testing.T) {
+		if _, err := middleware.ParseTrustedProxies([]string{"10.0.0.300"}); err == nil {
+			t.Error("esperado erro para endereço inválido")
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"expenseapi/internal/currency"
+	"expenseapi/internal/model"
+)
+
+const ecbSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-03-04">
+			<Cube currency="USD" rate="1.0850"/>
+			<Cube currency="BRL" rate="5.3700"/>
+		</Cube>
+		<Cube time="2024-03-01">
+			<Cube currency="USD" rate="1.0800"/>
+			<Cube currency="BRL" rate="5.4000"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func date(value string) time.Time {
+	d, _ := time.Parse("2006-01-02", value)
+	return d
+}
+
+func TestCurrencyParse(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		rates, err := currency.ParseCSV(strings.NewReader("date,currency,rate\n2024-03-01,usd,1.0800\n2024-03-01,BRL,5.4\n"))
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		if len(rates) != 2 {
+			t.Fatalf("esperadas 2 cotações, obtidas %d", len(rates))
+		}
+		if rates[0].Currency != "USD" || rates[0].Rate != "1.08" || !rates[0].Date.Equal(date("2024-03-01")) {
+			t.Errorf("cotação inesperada: %+v", rates[0])
+		}
+	})
+
+	t.Run("csv_invalido", func(t *testing.T) {
+		for _, input := range []string{
+			"2024-03-01,USD,0\n",
+			"2024-03-01,USD,-1\n",
+			"2024-03-01,USD,1e3\n",
+			"2024-03-01,DOLAR,1.08\n",
+			"2024-03-01,EUR,1\n",
+			"01/03/2024,USD,1.08\n",
+		} {
+			if _, err := currency.ParseCSV(strings.NewReader(input)); err == nil {
+				t.Errorf("%q: esperado erro", input)
+			}
+		}
+	})
+
+	t.Run("ecb", func(t *testing.T) {
+		rates, err := currency.ParseECB(strings.NewReader(ecbSample))
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		if len(rates) != 4 {
+			t.Fatalf("esperadas 4 cotações, obtidas %d", len(rates))
+		}
+		if rates[1].Currency != "BRL" || rates[1].Rate != "5.37" || !rates[1].Date.Equal(date("2024-03-04")) {
+			t.Errorf("cotação inesperada: %+v", rates[1])
+		}
+	})
+}
+
+func TestCurrencyTable(t *testing.T) {
+	rates, err := currency.ParseECB(strings.NewReader(ecbSample))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	table := currency.NewTable()
+	if err := table.Add(rates...); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	t.Run("converte_com_a_cotacao_da_data", func(t *testing.T) {
+		// 10,00 USD * 5,40 / 1,08 = 50,00 BRL
+		got, err := table.Convert(model.MustParseMoney("10.00"), "USD", "BRL", date("2024-03-01"))
+		if err != nil || got.String() != "50.00" {
+			t.Errorf("esperado 50.00, obtido %s (%v)", got, err)
+		}
+
+		// No fim de semana vale a cotação de sexta-feira
+		got, err = table.Convert(model.MustParseMoney("10.00"), "USD", "BRL", date("2024-03-03"))
+		if err != nil || got.String() != "50.00" {
+			t.Errorf("esperado 50.00, obtido %s (%v)", got, err)
+		}
+	})
+
+	t.Run("arredonda_para_o_centavo", func(t *testing.T) {
+		// 1,00 EUR = 1,085 USD, arredondado para cima
+		got, err := table.Convert(model.MustParseMoney("1.00"), "EUR", "USD", date("2024-03-04"))
+		if err != nil || got.String() != "1.09" {
+			t.Errorf("esperado 1.09, obtido %s (%v)", got, err)
+		}
+		// 10,00 BRL = 10 * 1,085 / 5,37 = 2,0204... USD
+		got, err = table.Convert(model.MustParseMoney("10.00"), "BRL", "USD", date("2024-03-04"))
+		if err != nil || got.String() != "2.02" {
+			t.Errorf("esperado 2.02, obtido %s (%v)", got, err)
+		}
+	})
+
+	t.Run("sem_cotacao", func(t *testing.T) {
+		if _, err := table.Convert(model.MustParseMoney("1.00"), "USD", "BRL", date("2024-02-29")); !errors.Is(err, currency.ErrRateNotFound) {
+			t.Errorf("esperado ErrRateNotFound, obtido %v", err)
+		}
+		if _, err := table.Convert(model.MustParseMoney("1.00"), "JPY", "BRL", date("2024-03-04")); !errors.Is(err, currency.ErrRateNotFound) {
+			t.Errorf("esperado ErrRateNotFound, obtido %v", err)
+		}
+		if got, err := table.Convert(model.MustParseMoney("1.00"), "JPY", "JPY", date("2024-03-04")); err != nil || got.String() != "1.00" {
+			t.Errorf("a conversão para a mesma moeda não deveria exigir cotação: %s (%v)", got, err)
+		}
+	})
+
+	t.Run("substitui_a_cotacao_da_mesma_data", func(t *testing.T) {
+		if err := table.Add(model.ExchangeRate{Date: date("2024-03-01"), Currency: "USD", Rate: "1.2"}); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		got, err := table.Convert(model.MustParseMoney("12.00"), "USD", "EUR", date("2024-03-01"))
+		if err != nil || got.String() != "10.00" {
+			t.Errorf("esperado 10.00, obtido %s (%v)", got, err)
+		}
+	})
+
+	t.Run("moedas_suportadas", func(t *testing.T) {
+		if !table.Has("EUR") || !table.Has("BRL") || table.Has("JPY") {
+			t.Error("moedas suportadas inesperadas")
+		}
+	})
+}
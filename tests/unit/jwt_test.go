@@ -1,11 +1,26 @@
 package unit
 
 import (
+	"context"
 	"expenseapi/internal/auth"
+	"expenseapi/internal/middleware"
+	"expenseapi/internal/model"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
 
+// tokenAuthenticator valida apenas a assinatura e o tipo do token, sem
+// consultar revogações, para exercitar os middlewares isoladamente
+type tokenAuthenticator struct {
+	jwtService *auth.JWTService
+}
+
+func (a tokenAuthenticator) Authenticate(ctx context.Context, token string) (*auth.Claims, error) {
+	return a.jwtService.ValidateToken(token, auth.TokenTypeAccess)
+}
+
 func TestJWTService(t *testing.T) {
 	// Configuração do serviço
 	secretKey := "test_secret_key"
@@ -31,7 +46,7 @@ func TestJWTService(t *testing.T) {
 		}
 
 		// Valida o token de acesso
-		claims, err := jwtService.ValidateToken(token)
+		claims, err := jwtService.ValidateToken(token, auth.TokenTypeAccess)
 		if err != nil {
 			t.Fatalf("erro ao validar token: %v", err)
 		}
@@ -44,7 +59,7 @@ func TestJWTService(t *testing.T) {
 
 	t.Run("token_invalido", func(t *testing.T) {
 		// Tenta validar um token inválido
-		_, err := jwtService.ValidateToken("invalid.token.here")
+		_, err := jwtService.ValidateToken("invalid.token.here", auth.TokenTypeAccess)
 		if err == nil {
 			t.Error("esperado erro ao validar token inválido")
 		}
@@ -64,9 +79,133 @@ func TestJWTService(t *testing.T) {
 		time.Sleep(2 * time.Second)
 
 		// Tenta validar o token expirado
-		_, err = shortJWT.ValidateToken(token)
+		_, err = shortJWT.ValidateToken(token, auth.TokenTypeAccess)
 		if err == nil {
 			t.Error("esperado erro ao validar token expirado")
 		}
 	})
+	t.Run("refresh_token_nao_vale_como_acesso", func(t *testing.T) {
+		token, refreshToken, err := jwtService.GenerateToken("test-user-id")
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+
+		// O refresh token não pode ser usado como token de acesso
+		if _, err := jwtService.ValidateToken(refreshToken, auth.TokenTypeAccess); err == nil {
+			t.Error("esperado erro ao validar refresh token como token de acesso")
+		}
+
+		// E o token de acesso não pode ser usado como refresh token
+		if _, err := jwtService.ValidateToken(token, auth.TokenTypeRefresh); err == nil {
+			t.Error("esperado erro ao validar token de acesso como refresh token")
+		}
+
+		// Cada token continua válido para o seu próprio tipo
+		if _, err := jwtService.ValidateToken(refreshToken, auth.TokenTypeRefresh); err != nil {
+			t.Errorf("erro ao validar refresh token: %v", err)
+		}
+	})
+
+	t.Run("middleware_rejeita_refresh_token", func(t *testing.T) {
+		token, refreshToken, err := jwtService.GenerateToken("test-user-id")
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+
+		handler := middleware.AuthMiddleware(tokenAuthenticator{jwtService}, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		tests := []struct {
+			name         string
+			token        string
+			expectedCode int
+		}{
+			{name: "token_de_acesso", token: token, expectedCode: http.StatusOK},
+			{name: "refresh_token", token: refreshToken, expectedCode: http.StatusUnauthorized},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil)
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+
+				w := httptest.NewRecorder()
+				handler(w, req)
+
+				if w.Code != tt.expectedCode {
+					t.Errorf("código de status esperado %d, obtido %d", tt.expectedCode, w.Code)
+				}
+			})
+		}
+	})
+	t.Run("middleware_bloqueia_escrita_somente_leitura", func(t *testing.T) {
+		token, _, err := jwtService.GenerateToken("test-user-id", auth.WithReadOnly(true))
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+
+		handler := middleware.AuthMiddleware(tokenAuthenticator{jwtService}, middleware.RequireWriteAccess(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("código de status esperado %d, obtido %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("escopos_de_token_pessoal", func(t *testing.T) {
+		session := &auth.Claims{Type: auth.TokenTypeAccess}
+		personal := &auth.Claims{Type: auth.TokenTypePersonal, Scopes: []string{auth.ScopeExpensesRead}}
+
+		if !session.HasScope(auth.ScopeExpensesWrite) {
+			t.Error("token de sessão deveria ter acesso completo")
+		}
+		if !personal.HasScope(auth.ScopeExpensesRead) {
+			t.Error("token pessoal deveria ter o escopo concedido")
+		}
+		if personal.HasScope(auth.ScopeExpensesWrite) {
+			t.Error("token pessoal não deveria ter escopo não concedido")
+		}
+	})
+
+	t.Run("middleware_exige_papel", func(t *testing.T) {
+		handler := middleware.AuthMiddleware(tokenAuthenticator{jwtService}, middleware.RequireRole(model.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		tests := []struct {
+			name     string
+			opts     []auth.TokenOption
+			expected int
+		}{
+			{name: "administrador", opts: []auth.TokenOption{auth.WithRole(model.RoleAdmin)}, expected: http.StatusOK},
+			{name: "usuario_comum", opts: []auth.TokenOption{auth.WithRole(model.RoleUser)}, expected: http.StatusForbidden},
+			{name: "token_sem_papel", expected: http.StatusForbidden},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				token, _, err := jwtService.GenerateToken("test-user-id", tt.opts...)
+				if err != nil {
+					t.Fatalf("erro ao gerar token: %v", err)
+				}
+
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				w := httptest.NewRecorder()
+				handler(w, req)
+
+				if w.Code != tt.expected {
+					t.Errorf("código de status esperado %d, obtido %d", tt.expected, w.Code)
+				}
+			})
+		}
+	})
 }
@@ -0,0 +1,138 @@
+package unit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"expenseapi/internal/auth"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey grava a chave privada em PEM (PKCS#8) e retorna o caminho
+func writeKey(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("erro ao serializar chave: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("erro ao gravar chave: %v", err)
+	}
+	return path
+}
+
+func TestAsymmetricJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave RSA: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave Ed25519: %v", err)
+	}
+
+	oldKey, err := auth.LoadSigningKey("2025-01", writeKey(t, rsaKey))
+	if err != nil {
+		t.Fatalf("erro ao carregar chave RSA: %v", err)
+	}
+	newKey, err := auth.LoadSigningKey("2025-02", writeKey(t, edKey))
+	if err != nil {
+		t.Fatalf("erro ao carregar chave Ed25519: %v", err)
+	}
+
+	oldSet, err := auth.NewKeySet("2025-01", oldKey)
+	if err != nil {
+		t.Fatalf("erro ao criar conjunto de chaves: %v", err)
+	}
+	oldService := auth.NewJWTServiceWithKeys(oldSet, "", time.Hour, 24*time.Hour)
+
+	t.Run("assina_com_a_chave_ativa", func(t *testing.T) {
+		token, _, err := oldService.GenerateToken("test-user-id")
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &auth.Claims{})
+		if err != nil {
+			t.Fatalf("erro ao ler token: %v", err)
+		}
+		if parsed.Header["kid"] != "2025-01" || parsed.Method.Alg() != "RS256" {
+			t.Errorf("cabeçalho inesperado: %v", parsed.Header)
+		}
+	})
+
+	t.Run("rotacao_mantem_tokens_antigos_validos", func(t *testing.T) {
+		token, _, err := oldService.GenerateToken("test-user-id")
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+
+		rotated, err := auth.NewKeySet("2025-02", oldKey, newKey)
+		if err != nil {
+			t.Fatalf("erro ao criar conjunto de chaves: %v", err)
+		}
+		service := auth.NewJWTServiceWithKeys(rotated, "", time.Hour, 24*time.Hour)
+
+		if _, err := service.ValidateToken(token, auth.TokenTypeAccess); err != nil {
+			t.Errorf("token assinado com a chave anterior deveria ser válido: %v", err)
+		}
+
+		newToken, _, err := service.GenerateToken("test-user-id")
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+		if _, err := oldService.ValidateToken(newToken, auth.TokenTypeAccess); err == nil {
+			t.Error("serviço sem a nova chave não deveria validar o token")
+		}
+	})
+
+	t.Run("rejeita_hs256_sem_segredo_legado", func(t *testing.T) {
+		legacy := auth.NewJWTService("test_secret_key", time.Hour, 24*time.Hour)
+		token, _, err := legacy.GenerateToken("test-user-id")
+		if err != nil {
+			t.Fatalf("erro ao gerar token: %v", err)
+		}
+
+		if _, err := oldService.ValidateToken(token, auth.TokenTypeAccess); err == nil {
+			t.Error("token HS256 não deveria ser aceito")
+		}
+
+		migrating := auth.NewJWTServiceWithKeys(oldSet, "test_secret_key", time.Hour, 24*time.Hour)
+		if _, err := migrating.ValidateToken(token, auth.TokenTypeAccess); err != nil {
+			t.Errorf("token HS256 deveria ser aceito durante a migração: %v", err)
+		}
+	})
+
+	t.Run("jwks_publica_apenas_chaves_publicas", func(t *testing.T) {
+		set, err := auth.NewKeySet("2025-02", oldKey, newKey)
+		if err != nil {
+			t.Fatalf("erro ao criar conjunto de chaves: %v", err)
+		}
+		jwks := auth.NewJWTServiceWithKeys(set, "", time.Hour, 24*time.Hour).JWKS()
+
+		if len(jwks.Keys) != 2 {
+			t.Fatalf("esperadas 2 chaves, obtidas %d", len(jwks.Keys))
+		}
+		if jwks.Keys[0].Kty != "RSA" || jwks.Keys[0].N == "" || jwks.Keys[0].E != "AQAB" {
+			t.Errorf("JWK RSA inesperado: %+v", jwks.Keys[0])
+		}
+		if jwks.Keys[1].Kty != "OKP" || jwks.Keys[1].Crv != "Ed25519" || jwks.Keys[1].X == "" {
+			t.Errorf("JWK Ed25519 inesperado: %+v", jwks.Keys[1])
+		}
+	})
+
+	t.Run("chave_ativa_exige_parte_privada", func(t *testing.T) {
+		public := &auth.SigningKey{ID: "pub", Method: jwt.SigningMethodRS256, Public: &rsaKey.PublicKey}
+		if _, err := auth.NewKeySet("pub", public); err == nil {
+			t.Error("chave apenas pública não deveria ser aceita como ativa")
+		}
+	})
+}
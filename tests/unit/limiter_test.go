@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"context"
+	"expenseapi/internal/auth"
+	"testing"
+	"time"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	ctx := context.Background()
+	policy := auth.LimitPolicy{Threshold: 2, BaseDelay: time.Minute, MaxDelay: 4 * time.Minute, Window: time.Hour}
+
+	t.Run("deve bloquear a conta após exceder o limite com backoff exponencial", func(t *testing.T) {
+		limiter := auth.NewLoginLimiter(auth.NewMemoryAttemptStore(), policy, policy)
+
+		for i := 0; i < 2; i++ {
+			if err := limiter.Failure(ctx, "user@example.com", ""); err != nil {
+				t.Fatalf("erro ao registrar falha: %v", err)
+			}
+		}
+		if wait, _ := limiter.Check(ctx, "user@example.com", ""); wait != 0 {
+			t.Fatalf("conta não deveria estar bloqueada dentro do limite, espera %v", wait)
+		}
+
+		expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute}
+		for _, delay := range expected {
+			if err := limiter.Failure(ctx, "user@example.com", ""); err != nil {
+				t.Fatalf("erro ao registrar falha: %v", err)
+			}
+			wait, err := limiter.Check(ctx, "user@example.com", "")
+			if err != nil {
+				t.Fatalf("erro ao verificar bloqueio: %v", err)
+			}
+			if wait <= delay-time.Second || wait > delay {
+				t.Errorf("bloqueio esperado de %v, obtido %v", delay, wait)
+			}
+		}
+	})
+
+	t.Run("deve bloquear o IP independentemente da conta", func(t *testing.T) {
+		limiter := auth.NewLoginLimiter(auth.NewMemoryAttemptStore(), policy, policy)
+
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+			if err := limiter.Failure(ctx, email, "10.0.0.1"); err != nil {
+				t.Fatalf("erro ao registrar falha: %v", err)
+			}
+		}
+
+		if wait, _ := limiter.Check(ctx, "d@example.com", "10.0.0.1"); wait == 0 {
+			t.Error("IP deveria estar bloqueado")
+		}
+		if wait, _ := limiter.Check(ctx, "d@example.com", "10.0.0.2"); wait != 0 {
+			t.Error("outro IP não deveria estar bloqueado")
+		}
+	})
+
+	t.Run("deve zerar o contador da conta após login bem-sucedido", func(t *testing.T) {
+		limiter := auth.NewLoginLimiter(auth.NewMemoryAttemptStore(), policy, policy)
+
+		for i := 0; i < 3; i++ {
+			limiter.Failure(ctx, "user@example.com", "")
+		}
+		if err := limiter.Success(ctx, "user@example.com"); err != nil {
+			t.Fatalf("erro ao registrar sucesso: %v", err)
+		}
+		if wait, _ := limiter.Check(ctx, "user@example.com", ""); wait != 0 {
+			t.Errorf("conta não deveria estar bloqueada após sucesso, espera %v", wait)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"expenseapi/internal/model"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("soma_exata", func(t *testing.T) {
+		sum := model.MustParseMoney("0.1").Add(model.MustParseMoney("0.2"))
+		if sum != model.MustParseMoney("0.3") {
+			t.Errorf("esperado 0.30, obtido %s", sum)
+		}
+
+		// Com float64, somar 0.1 dez vezes não resulta em 1
+		values := make([]model.Money, 10)
+		for i := range values {
+			values[i] = model.MustParseMoney("0.10")
+		}
+		if total := model.SumMoney(values...); total.String() != "1.00" {
+			t.Errorf("esperado 1.00, obtido %s", total)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		cases := map[string]int64{
+			"150.50":      15050,
+			"150.5":       15050,
+			"150":         15000,
+			"0.01":        1,
+			"-0.30":       -30,
+			"00012.3":     1230,
+			"99999999.99": 9999999999,
+		}
+		for input, cents := range cases {
+			got, err := model.ParseMoney(input)
+			if err != nil {
+				t.Errorf("%q: erro inesperado %v", input, err)
+				continue
+			}
+			if got.Cents() != cents {
+				t.Errorf("%q: esperado %d centavos, obtido %d", input, cents, got.Cents())
+			}
+		}
+	})
+
+	t.Run("parse_invalido", func(t *testing.T) {
+		cases := map[string]error{
+			"10.005":       model.ErrAmountScale,
+			"0.001":        model.ErrAmountScale,
+			"100000000.00": model.ErrAmountRange,
+			"":             model.ErrInvalidAmount,
+			"abc":          model.ErrInvalidAmount,
+			"1.":           model.ErrInvalidAmount,
+			".5":           model.ErrInvalidAmount,
+			"1e2":          model.ErrInvalidAmount,
+			"1,50":         model.ErrInvalidAmount,
+			"+1":           model.ErrInvalidAmount,
+		}
+		for input, expected := range cases {
+			if _, err := model.ParseMoney(input); !errors.Is(err, expected) {
+				t.Errorf("%q: esperado %v, obtido %v", input, expected, err)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(struct {
+			Amount model.Money `json:"amount"`
+		}{model.MustParseMoney("42.5")})
+		if err != nil {
+			t.Fatalf("erro ao serializar: %v", err)
+		}
+		if string(data) != `{"amount":42.50}` {
+			t.Errorf("serialização inesperada: %s", data)
+		}
+
+		for _, body := range []string{`{"amount":42.5}`, `{"amount":"42.50"}`} {
+			var input struct {
+				Amount model.Money `json:"amount"`
+			}
+			if err := json.Unmarshal([]byte(body), &input); err != nil {
+				t.Errorf("%s: erro inesperado %v", body, err)
+				continue
+			}
+			if input.Amount.Cents() != 4250 {
+				t.Errorf("%s: esperado 4250 centavos, obtido %d", body, input.Amount.Cents())
+			}
+		}
+
+		var input struct {
+			Amount model.Money `json:"amount"`
+		}
+		if err := json.Unmarshal([]byte(`{"amount":0.105}`), &input); !errors.Is(err, model.ErrAmountScale) {
+			t.Errorf("esperado ErrAmountScale, obtido %v", err)
+		}
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		var m model.Money
+		if err := m.Scan("150.5000"); err != nil || m.Cents() != 15050 {
+			t.Errorf("esperado 15050 centavos, obtido %d (%v)", m.Cents(), err)
+		}
+		if err := m.Scan(int64(3)); err != nil || m.Cents() != 300 {
+			t.Errorf("esperado 300 centavos, obtido %d (%v)", m.Cents(), err)
+		}
+		if err := m.Scan("123456789012.34"); err != nil || m.String() != "123456789012.34" {
+			t.Errorf("somas acima do limite da coluna devem ser lidas, obtido %s (%v)", m, err)
+		}
+		if err := m.Scan("1.005"); !errors.Is(err, model.ErrAmountScale) {
+			t.Errorf("esperado ErrAmountScale, obtido %v", err)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+package unit
+
+import (
+	"expenseapi/internal/oidc"
+	"testing"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// Vetor do apêndice B da RFC 7636
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	expected := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if challenge := oidc.CodeChallenge(verifier); challenge != expected {
+		t.Errorf("code_challenge esperado %s, obtido %s", expected, challenge)
+	}
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"expenseapi/internal/password"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	params := password.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	argon := password.NewArgon2idHasher(params)
+	manager := password.NewManager(argon, password.NewBcryptHasher(bcrypt.MinCost))
+
+	t.Run("argon2id_gera_e_confere", func(t *testing.T) {
+		hash, err := manager.Hash("senha123")
+		if err != nil {
+			t.Fatalf("erro ao gerar hash: %v", err)
+		}
+		if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+			t.Errorf("formato inesperado: %s", hash)
+		}
+
+		if err := manager.Verify(hash, "senha123"); err != nil {
+			t.Errorf("esperada senha válida, obtido %v", err)
+		}
+		if err := manager.Verify(hash, "senha124"); !errors.Is(err, password.ErrMismatch) {
+			t.Errorf("esperado ErrMismatch, obtido %v", err)
+		}
+		if manager.NeedsRehash(hash) {
+			t.Error("hash com os parâmetros atuais não deveria precisar de atualização")
+		}
+	})
+
+	t.Run("salt_diferente_a_cada_hash", func(t *testing.T) {
+		first, _ := manager.Hash("senha123")
+		second, _ := manager.Hash("senha123")
+		if first == second {
+			t.Error("hashes da mesma senha deveriam ser diferentes")
+		}
+	})
+
+	t.Run("bcrypt_legado_e_aceito_e_atualizado", func(t *testing.T) {
+		legacy, err := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("erro ao gerar hash bcrypt: %v", err)
+		}
+
+		if err := manager.Verify(string(legacy), "senha123"); err != nil {
+			t.Errorf("esperada senha válida, obtido %v", err)
+		}
+		if err := manager.Verify(string(legacy), "errada"); !errors.Is(err, password.ErrMismatch) {
+			t.Errorf("esperado ErrMismatch, obtido %v", err)
+		}
+		if !manager.NeedsRehash(string(legacy)) {
+			t.Error("hash bcrypt deveria precisar de atualização")
+		}
+	})
+
+	t.Run("parametros_mais_fracos_exigem_atualizacao", func(t *testing.T) {
+		weaker := password.NewArgon2idHasher(password.Argon2idParams{Memory: 512, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+		hash, err := weaker.Hash("senha123")
+		if err != nil {
+			t.Fatalf("erro ao gerar hash: %v", err)
+		}
+
+		if err := manager.Verify(hash, "senha123"); err != nil {
+			t.Errorf("hash com parâmetros antigos deveria continuar válido: %v", err)
+		}
+		if !manager.NeedsRehash(hash) {
+			t.Error("hash com menos memória deveria precisar de atualização")
+		}
+	})
+
+	t.Run("formato_desconhecido", func(t *testing.T) {
+		for _, hash := range []string{"", "texto-puro", "$argon2id$v=19$m=1024$abc$def", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$aGFzaA"} {
+			if err := manager.Verify(hash, "senha123"); err == nil {
+				t.Errorf("hash %q deveria ser rejeitado", hash)
+			}
+			if !manager.NeedsRehash(hash) {
+				t.Errorf("hash %q deveria precisar de atualização", hash)
+			}
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"expenseapi/internal/repository"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	t.Run("sem_condicoes", func(t *testing.T) {
+		query, args := repository.NewQueryBuilder("  SELECT id FROM expenses e  ").Build()
+		if query != "SELECT id FROM expenses e" {
+			t.Errorf("consulta inesperada: %q", query)
+		}
+		if len(args) != 0 {
+			t.Errorf("esperados 0 argumentos, obtidos %d", len(args))
+		}
+	})
+
+	t.Run("condicoes_ordenacao_e_limite", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		query, args := repository.NewQueryBuilder("SELECT id FROM expenses e").
+			Equal("e.user_id", "user-1").
+			Range("e.date", &start, nil).
+			In("e.currency", "BRL", "USD").
+			Where("lower(e.description) LIKE lower(?)", "%mercado%").
+			GroupBy("e.id").
+			OrderBy("e.date", true).
+			OrderBy("e.id", false).
+			Limit(10).
+			Build()
+
+		expected := "SELECT id FROM expenses e WHERE e.user_id = $1 AND e.date >= $2 AND e.currency IN ($3, $4)" +
+			" AND lower(e.description) LIKE lower($5) GROUP BY e.id ORDER BY e.date DESC, e.id ASC LIMIT $6"
+		if query != expected {
+			t.Errorf("consulta inesperada:\n%s\nesperada:\n%s", query, expected)
+		}
+		expectedArgs := []interface{}{"user-1", &start, "BRL", "USD", "%mercado%", 10}
+		if !reflect.DeepEqual(args, expectedArgs) {
+			t.Errorf("argumentos inesperados: %v", args)
+		}
+	})
+
+	t.Run("mais_de_nove_parametros", func(t *testing.T) {
+		q := repository.NewQueryBuilder("SELECT id FROM expenses e")
+		values := make([]interface{}, 12)
+		for i := range values {
+			values[i] = i
+		}
+		q.In("e.amount", values...)
+		query, args := q.Build()
+
+		if !strings.Contains(query, "$9, $10, $11, $12)") {
+			t.Errorf("marcadores inesperados: %s", query)
+		}
+		if len(args) != 12 {
+			t.Errorf("esperados 12 argumentos, obtidos %d", len(args))
+		}
+		for i := 1; i <= 12; i++ {
+			if !strings.Contains(query, "$"+strconv.Itoa(i)) {
+				t.Errorf("marcador $%d ausente: %s", i, query)
+			}
+		}
+	})
+
+	t.Run("intervalo_com_ponteiros_nulos", func(t *testing.T) {
+		var start, end *time.Time
+		query, args := repository.NewQueryBuilder("SELECT id FROM expenses e").Range("e.date", start, end).Build()
+		if query != "SELECT id FROM expenses e" || len(args) != 0 {
+			t.Errorf("intervalo aberto não deveria gerar condições: %q %v", query, args)
+		}
+
+		query, args = repository.NewQueryBuilder("SELECT id FROM expenses e").Range("e.amount", 1, 5).Build()
+		if query != "SELECT id FROM expenses e WHERE e.amount >= $1 AND e.amount <= $2" {
+			t.Errorf("consulta inesperada: %q", query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{1, 5}) {
+			t.Errorf("argumentos inesperados: %v", args)
+		}
+	})
+
+	t.Run("lista_vazia", func(t *testing.T) {
+		query, args := repository.NewQueryBuilder("SELECT id FROM expenses e").In("e.currency").Build()
+		if query != "SELECT id FROM expenses e WHERE FALSE" || len(args) != 0 {
+			t.Errorf("lista vazia não deveria corresponder a nenhuma linha: %q %v", query, args)
+		}
+	})
+
+	t.Run("parametro_reutilizado", func(t *testing.T) {
+		q := repository.NewQueryBuilder("SELECT id FROM expenses e").Equal("e.user_id", "user-1")
+		tags := q.Arg([]string{"a", "b"})
+		q.Where("tag_count(e.id, " + tags + ") = cardinality(" + tags + ")")
+		query, args := q.Build()
+
+		if query != "SELECT id FROM expenses e WHERE e.user_id = $1 AND tag_count(e.id, $2) = cardinality($2)" {
+			t.Errorf("consulta inesperada: %q", query)
+		}
+		if len(args) != 2 {
+			t.Errorf("esperados 2 argumentos, obtidos %d", len(args))
+		}
+	})
+
+	t.Run("argumentos_do_inicio", func(t *testing.T) {
+		query, args := repository.NewQueryBuilder("SELECT id FROM expenses e CROSS JOIN to_tsquery($1) AS tsq", "farmacia").
+			Equal("e.user_id", "user-1").
+			Build()
+
+		if query != "SELECT id FROM expenses e CROSS JOIN to_tsquery($1) AS tsq WHERE e.user_id = $2" {
+			t.Errorf("consulta inesperada: %q", query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{"farmacia", "user-1"}) {
+			t.Errorf("argumentos inesperados: %v", args)
+		}
+	})
+
+	t.Run("build_repetido", func(t *testing.T) {
+		q := repository.NewQueryBuilder("SELECT id FROM expenses e").Equal("e.user_id", "user-1").Limit(5)
+		first, firstArgs := q.Build()
+		second, secondArgs := q.Build()
+		if first != second || !reflect.DeepEqual(firstArgs, secondArgs) {
+			t.Errorf("Build deveria ser idempotente: %q %v / %q %v", first, firstArgs, second, secondArgs)
+		}
+	})
+
+	t.Run("marcadores_e_valores_diferentes", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("esperado panic com quantidade errada de valores")
+			}
+		}()
+		repository.NewQueryBuilder("SELECT 1").Where("a = ? AND b = ?", 1)
+	})
+}
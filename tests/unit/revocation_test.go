@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"context"
+	"expenseapi/internal/auth"
+	"expenseapi/internal/model"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memoryRevocationStore é uma implementação em memória de auth.RevocationStore
+type memoryRevocationStore struct {
+	tokens   []model.RevokedToken
+	users    []model.UserRevocation
+	sessions []model.RevokedSession
+}
+
+func (s *memoryRevocationStore) RevokeToken(ctx context.Context, token model.RevokedToken) error {
+	s.tokens = append(s.tokens, token)
+	return nil
+}
+
+func (s *memoryRevocationStore) RevokeUser(ctx context.Context, revocation model.UserRevocation) error {
+	s.users = append(s.users, revocation)
+	return nil
+}
+
+func (s *memoryRevocationStore) RevokeSession(ctx context.Context, session model.RevokedSession) error {
+	s.sessions = append(s.sessions, session)
+	return nil
+}
+
+func (s *memoryRevocationStore) ListActive(ctx context.Context, now time.Time) ([]model.RevokedToken, []model.UserRevocation, []model.RevokedSession, error) {
+	return s.tokens, s.users, s.sessions, nil
+}
+
+func (s *memoryRevocationStore) PurgeExpired(ctx context.Context, now time.Time) error {
+	var tokens []model.RevokedToken
+	for _, token := range s.tokens {
+		if token.ExpiresAt.After(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	var users []model.UserRevocation
+	for _, user := range s.users {
+		if user.ExpiresAt.After(now) {
+			users = append(users, user)
+		}
+	}
+	var sessions []model.RevokedSession
+	for _, session := range s.sessions {
+		if session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	s.tokens, s.users, s.sessions = tokens, users, sessions
+	return nil
+}
+
+func newClaims(id, userID string, issuedAt, expiresAt time.Time) *auth.Claims {
+	return &auth.Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+}
+
+func TestRevocationList(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("revoga_token_pelo_jti", func(t *testing.T) {
+		list := auth.NewRevocationList(&memoryRevocationStore{})
+		revoked := newClaims("jti-1", "user-1", now, now.Add(time.Hour))
+		other := newClaims("jti-2", "user-1", now, now.Add(time.Hour))
+
+		if err := list.RevokeToken(ctx, revoked); err != nil {
+			t.Fatalf("erro ao revogar token: %v", err)
+		}
+
+		if !list.IsRevoked(revoked) {
+			t.Error("esperado token revogado")
+		}
+		if list.IsRevoked(other) {
+			t.Error("outro token do mesmo usuário não deveria estar revogado")
+		}
+	})
+
+	t.Run("revoga_tokens_anteriores_do_usuario", func(t *testing.T) {
+		list := auth.NewRevocationList(&memoryRevocationStore{})
+		old := newClaims("jti-1", "user-1", now.Add(-time.Minute), now.Add(time.Hour))
+		newer := newClaims("jti-2", "user-1", now.Add(time.Minute), now.Add(time.Hour))
+		otherUser := newClaims("jti-3", "user-2", now.Add(-time.Minute), now.Add(time.Hour))
+
+		if err := list.RevokeUser(ctx, "user-1", now, time.Hour); err != nil {
+			t.Fatalf("erro ao revogar tokens do usuário: %v", err)
+		}
+
+		if !list.IsRevoked(old) {
+			t.Error("esperado token anterior revogado")
+		}
+		if list.IsRevoked(newer) {
+			t.Error("token emitido após a revogação não deveria estar revogado")
+		}
+		if list.IsRevoked(otherUser) {
+			t.Error("token de outro usuário não deveria estar revogado")
+		}
+	})
+
+	t.Run("revoga_tokens_da_sessao", func(t *testing.T) {
+		list := auth.NewRevocationList(&memoryRevocationStore{})
+		revoked := newClaims("jti-1", "user-1", now, now.Add(time.Hour))
+		revoked.SessionID = "session-1"
+		other := newClaims("jti-2", "user-1", now, now.Add(time.Hour))
+		other.SessionID = "session-2"
+
+		if err := list.RevokeSession(ctx, "session-1", "user-1", time.Hour); err != nil {
+			t.Fatalf("erro ao revogar sessão: %v", err)
+		}
+
+		if !list.IsRevoked(revoked) {
+			t.Error("esperado token da sessão revogado")
+		}
+		if list.IsRevoked(other) {
+			t.Error("token de outra sessão não deveria estar revogado")
+		}
+	})
+
+	t.Run("sincronizacao_remove_expirados", func(t *testing.T) {
+		store := &memoryRevocationStore{}
+		list := auth.NewRevocationList(store)
+		expired := newClaims("jti-1", "user-1", now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+		if err := list.RevokeToken(ctx, expired); err != nil {
+			t.Fatalf("erro ao revogar token: %v", err)
+		}
+		if err := list.Sync(ctx); err != nil {
+			t.Fatalf("erro ao sincronizar lista: %v", err)
+		}
+
+		if len(store.tokens) != 0 {
+			t.Errorf("esperado 0 tokens persistidos, obtido %d", len(store.tokens))
+		}
+		if list.IsRevoked(expired) {
+			t.Error("entrada expirada deveria ter sido removida da lista")
+		}
+	})
+}
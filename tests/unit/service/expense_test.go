@@ -3,14 +3,19 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"expenseapi/internal/currency"
 	"expenseapi/internal/model"
 	"expenseapi/internal/service"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockExpenseRepository é um mock do repositório de despesas
@@ -39,6 +44,14 @@ func (m *MockExpenseRepository) List(ctx context.Context, userID string, filter
 	return args.Get(0).([]*model.Expense), args.Error(1)
 }
 
+func (m *MockExpenseRepository) ListPage(ctx context.Context, userID string, filter *model.ExpenseFilter, page model.ExpensePageQuery) ([]*model.Expense, error) {
+	args := m.Called(ctx, userID, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Expense), args.Error(1)
+}
+
 func (m *MockExpenseRepository) Update(ctx context.Context, expense *model.Expense) error {
 	args := m.Called(ctx, expense)
 	return args.Error(0)
@@ -49,15 +62,103 @@ func (m *MockExpenseRepository) Delete(ctx context.Context, id string, userID st
 	return args.Error(0)
 }
 
+func (m *MockExpenseRepository) SummarizeGroups(ctx context.Context, userID string, filter *model.ExpenseFilter) ([]model.ExpenseGroupTotal, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ExpenseGroupTotal), args.Error(1)
+}
+
+func (m *MockExpenseRepository) Search(ctx context.Context, userID string, text string, filter *model.ExpenseFilter, limit int) ([]*model.ExpenseSearchResult, error) {
+	args := m.Called(ctx, userID, text, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ExpenseSearchResult), args.Error(1)
+}
+
+// fixedCurrency usa uma moeda base fixa e converte com uma tabela em memória
+type fixedCurrency struct {
+	base  string
+	rates *currency.Table
+}
+
+func newFixedCurrency(base string, rates ...model.ExchangeRate) fixedCurrency {
+	table := currency.NewTable()
+	table.Add(rates...)
+	return fixedCurrency{base: base, rates: table}
+}
+
+func (f fixedCurrency) BaseCurrency(ctx context.Context, userID string) (string, error) {
+	return f.base, nil
+}
+
+func (f fixedCurrency) Supported(code string) bool {
+	return f.rates.Has(code)
+}
+
+func (f fixedCurrency) Convert(amount model.Money, from, to string, date time.Time) (model.Money, error) {
+	return f.rates.Convert(amount, from, to, date)
+}
+
+// fixedCategories resolve as categorias padrão e arquiva as informadas
+type fixedCategories map[string]*model.UserCategory
+
+func newFixedCategories(archived ...model.Category) fixedCategories {
+	categories := make(fixedCategories)
+	for _, name := range []model.Category{
+		model.CategoryGroceries, model.CategoryLeisure, model.CategoryElectronics,
+		model.CategoryUtilities, model.CategoryClothing, model.CategoryHealth, model.CategoryOthers,
+	} {
+		categories[strings.ToLower(string(name))] = &model.UserCategory{ID: "cat-" + strings.ToLower(string(name)), Name: name}
+	}
+	for _, name := range archived {
+		categories[strings.ToLower(string(name))].Archived = true
+	}
+	return categories
+}
+
+// withChild cria a subcategoria name abaixo de parent
+func (f fixedCategories) withChild(parent, name model.Category) fixedCategories {
+	parentID := f[strings.ToLower(string(parent))].ID
+	f[strings.ToLower(string(name))] = &model.UserCategory{ID: "cat-" + strings.ToLower(string(name)), ParentID: &parentID, Name: name}
+	return f
+}
+
+func (f fixedCategories) List(ctx context.Context, userID string, includeArchived bool) ([]*model.UserCategory, error) {
+	categories := make([]*model.UserCategory, 0, len(f))
+	for _, category := range f {
+		if includeArchived || !category.Archived {
+			categories = append(categories, category)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return strings.ToLower(string(categories[i].Name)) < strings.ToLower(string(categories[j].Name))
+	})
+	return categories, nil
+}
+
+func (f fixedCategories) Resolve(ctx context.Context, userID string, name model.Category) (*model.UserCategory, error) {
+	category, ok := f[strings.ToLower(string(name))]
+	if !ok {
+		return nil, service.ErrCategoryNotFound
+	}
+	if category.Archived {
+		return nil, service.ErrCategoryArchived
+	}
+	return category, nil
+}
+
 func TestExpenseService_Create(t *testing.T) {
 	mockRepo := new(MockExpenseRepository)
-	service := service.NewExpenseService(mockRepo)
+	service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
 	ctx := context.Background()
 	userID := "user123"
 
 	t.Run("deve criar uma despesa com sucesso", func(t *testing.T) {
 		input := &model.CreateExpenseInput{
-			Amount:      100.50,
+			Amount:      model.MustParseMoney("100.50"),
 			Description: "Teste de despesa",
 			Category:    model.CategoryGroceries,
 			Date:        "2024-02-18",
@@ -73,13 +174,29 @@ func TestExpenseService_Create(t *testing.T) {
 		assert.Equal(t, input.Amount, expense.Amount)
 		assert.Equal(t, input.Description, expense.Description)
 		assert.Equal(t, input.Category, expense.Category)
+		assert.Equal(t, "cat-mantimentos", expense.CategoryID)
 
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("deve usar o nome cadastrado da categoria", func(t *testing.T) {
+		input := &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Description: "Cinema",
+			Category:    "lazer",
+			Date:        "2024-02-18",
+		}
+
+		expense, err := service.Create(ctx, userID, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.CategoryLeisure, expense.Category)
+		assert.Equal(t, "cat-lazer", expense.CategoryID)
+	})
+
 	t.Run("deve retornar erro quando a data é inválida", func(t *testing.T) {
 		input := &model.CreateExpenseInput{
-			Amount:      100.50,
+			Amount:      model.MustParseMoney("100.50"),
 			Description: "Teste de despesa",
 			Category:    model.CategoryGroceries,
 			Date:        "data-invalida",
@@ -90,11 +207,273 @@ func TestExpenseService_Create(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, expense)
 	})
+
+	t.Run("deve retornar erro quando o valor não é positivo", func(t *testing.T) {
+		input := &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("-1.00"),
+			Description: "Teste de despesa",
+			Category:    model.CategoryGroceries,
+			Date:        "2024-02-18",
+		}
+
+		expense, err := service.Create(ctx, userID, input)
+
+		assert.EqualError(t, err, "valor deve ser maior que zero")
+		assert.Nil(t, expense)
+	})
+}
+
+func TestExpenseService_Category(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewExpenseService(new(MockExpenseRepository), newFixedCategories(model.CategoryClothing), newFixedCurrency("BRL"))
+
+	cases := map[model.Category]error{
+		"VIAGEM":               service.ErrCategoryNotFound,
+		model.CategoryClothing: service.ErrCategoryArchived,
+	}
+	for name, expected := range cases {
+		expense, err := svc.Create(ctx, "user123", &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Description: "Teste de despesa",
+			Category:    name,
+			Date:        "2024-02-18",
+		})
+
+		assert.ErrorIs(t, err, expected, name)
+		assert.Nil(t, expense)
+	}
+
+	existing := &model.Expense{ID: "expense123", UserID: "user123", Amount: model.MustParseMoney("1.00"), Currency: "BRL", Category: model.CategoryOthers}
+	repo := new(MockExpenseRepository)
+	repo.On("GetByID", ctx, "expense123", "user123").Return(existing, nil)
+	svc = service.NewExpenseService(repo, newFixedCategories(model.CategoryClothing), newFixedCurrency("BRL"))
+
+	archived := model.CategoryClothing
+	_, err := svc.Update(ctx, "expense123", "user123", &model.UpdateExpenseInput{Category: &archived})
+	assert.ErrorIs(t, err, service.ErrCategoryArchived)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestExpenseService_Tags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deve normalizar as tags da despesa", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Expense")).Return(nil).Once()
+
+		expense, err := svc.Create(ctx, "user123", &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Description: "Hotel",
+			Category:    model.CategoryOthers,
+			Tags:        []string{" Viagem-2026", "REEMBOLSÁVEL", "viagem-2026"},
+			Date:        "2024-02-18",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"reembolsável", "viagem-2026"}, expense.Tags)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve recusar tags vazias, longas ou em excesso", func(t *testing.T) {
+		svc := service.NewExpenseService(new(MockExpenseRepository), newFixedCategories(), newFixedCurrency("BRL"))
+
+		many := make([]string, 21)
+		for i := range many {
+			many[i] = fmt.Sprintf("tag-%d", i)
+		}
+		cases := map[string][]string{
+			"vazia":     {"viagem", " "},
+			"longa":     {strings.Repeat("a", 51)},
+			"excedente": many,
+		}
+		for name, tags := range cases {
+			expense, err := svc.Create(ctx, "user123", &model.CreateExpenseInput{
+				Amount:      model.MustParseMoney("10.00"),
+				Description: "Hotel",
+				Category:    model.CategoryOthers,
+				Tags:        tags,
+				Date:        "2024-02-18",
+			})
+
+			assert.Error(t, err, name)
+			assert.Nil(t, expense)
+		}
+	})
+
+	t.Run("deve normalizar as tags do filtro", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		expected := &model.ExpenseFilter{Tags: []string{"reembolsável", "viagem"}, MatchAllTags: true}
+		mockRepo.On("List", ctx, "user123", expected).Return([]*model.Expense{}, nil).Once()
+
+		_, err := svc.List(ctx, "user123", &model.ExpenseFilter{Tags: []string{"Viagem", "", "reembolsável", "VIAGEM"}, MatchAllTags: true})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestExpenseService_ListPage(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	expenses := []*model.Expense{
+		{ID: "6f1c1d2e-0000-4000-8000-000000000003", Amount: model.MustParseMoney("3.00"), Currency: "BRL", Date: date},
+		{ID: "6f1c1d2e-0000-4000-8000-000000000002", Amount: model.MustParseMoney("2.00"), Currency: "BRL", Date: date},
+		{ID: "6f1c1d2e-0000-4000-8000-000000000001", Amount: model.MustParseMoney("1.00"), Currency: "BRL", Date: date},
+	}
+
+	t.Run("deve retornar o cursor da próxima página", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		// Uma despesa a mais que o limite indica que há uma próxima página
+		mockRepo.On("ListPage", ctx, userID, (*model.ExpenseFilter)(nil),
+			model.ExpensePageQuery{Sort: model.ExpenseSortAmount, Desc: true, Limit: 3}).Return(expenses, nil).Once()
+
+		page, err := svc.ListPage(ctx, userID, nil, model.ExpenseListParams{Limit: 2, Sort: "amount"})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		require.NotNil(t, page.NextCursor)
+		mockRepo.AssertExpectations(t)
+
+		// O cursor leva à despesa seguinte à última da página
+		after := &model.ExpenseCursor{Sort: model.ExpenseSortAmount, Desc: true, Value: "2.00", ID: expenses[1].ID}
+		mockRepo.On("ListPage", ctx, userID, (*model.ExpenseFilter)(nil),
+			model.ExpensePageQuery{Sort: model.ExpenseSortAmount, Desc: true, Limit: 3, After: after}).Return(expenses[2:], nil).Once()
+
+		page, err = svc.ListPage(ctx, userID, nil, model.ExpenseListParams{Limit: 2, Sort: "amount", Cursor: *page.NextCursor})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Nil(t, page.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve ordenar por data decrescente por padrão", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		mockRepo.On("ListPage", ctx, userID, (*model.ExpenseFilter)(nil),
+			model.ExpensePageQuery{Sort: model.ExpenseSortDate, Desc: true, Limit: 51}).Return([]*model.Expense{}, nil).Once()
+
+		page, err := svc.ListPage(ctx, userID, nil, model.ExpenseListParams{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.Nil(t, page.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve recusar parâmetros inválidos", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		mockRepo.On("ListPage", ctx, userID, (*model.ExpenseFilter)(nil), mock.Anything).Return(expenses, nil).Once()
+
+		page, err := svc.ListPage(ctx, userID, nil, model.ExpenseListParams{Limit: 2, Sort: "amount"})
+		require.NoError(t, err)
+
+		cases := map[string]model.ExpenseListParams{
+			"campo":                 {Sort: "user_id"},
+			"direção":               {Order: "up"},
+			"limite":                {Limit: 201},
+			"cursor malformado":     {Cursor: "não-é-um-cursor"},
+			"cursor de outro campo": {Limit: 2, Sort: "date", Cursor: *page.NextCursor},
+			"cursor de outra ordem": {Limit: 2, Sort: "amount", Order: "asc", Cursor: *page.NextCursor},
+		}
+		for name, params := range cases {
+			_, err := svc.ListPage(ctx, userID, nil, params)
+			assert.Error(t, err, name)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestExpenseService_MerchantNotes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deve gravar estabelecimento e observações sem espaços nas pontas", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Expense")).Return(nil).Once()
+
+		expense, err := svc.Create(ctx, "user123", &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("25.90"),
+			Description: "Remédios",
+			Merchant:    "  Farmácia São João ",
+			Notes:       "Receita do dentista\n",
+			Category:    model.CategoryHealth,
+			Date:        "2024-02-18",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Farmácia São João", expense.Merchant)
+		assert.Equal(t, "Receita do dentista", expense.Notes)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve recusar textos longos", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+
+		_, err := svc.Create(ctx, "user123", &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("25.90"),
+			Description: "Remédios",
+			Merchant:    strings.Repeat("á", 256),
+			Category:    model.CategoryHealth,
+			Date:        "2024-02-18",
+		})
+		assert.ErrorIs(t, err, service.ErrMerchantTooLong)
+
+		existing := &model.Expense{ID: "expense123", UserID: "user123", Amount: model.MustParseMoney("25.90"), Currency: "BRL"}
+		mockRepo.On("GetByID", ctx, "expense123", "user123").Return(existing, nil).Once()
+		notes := strings.Repeat("a", 2001)
+
+		_, err = svc.Update(ctx, "expense123", "user123", &model.UpdateExpenseInput{Notes: &notes})
+		assert.ErrorIs(t, err, service.ErrNotesTooLong)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestExpenseService_Search(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+
+	t.Run("deve buscar com o limite padrão e converter os valores", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		results := []*model.ExpenseSearchResult{{
+			Expense:    model.Expense{ID: "expense123", Amount: model.MustParseMoney("25.90"), Currency: "BRL", Description: "Farmácia"},
+			Rank:       0.1,
+			Highlights: model.ExpenseHighlights{Description: "<mark>Farmácia</mark>"},
+		}}
+		mockRepo.On("Search", ctx, userID, "farmacia", (*model.ExpenseFilter)(nil), 50).Return(results, nil).Once()
+
+		found, err := svc.Search(ctx, userID, "  farmacia ", nil, 0)
+
+		assert.NoError(t, err)
+		require.Len(t, found.Items, 1)
+		require.NotNil(t, found.Items[0].BaseAmount)
+		assert.Equal(t, model.MustParseMoney("25.90"), *found.Items[0].BaseAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve recusar busca vazia e limite inválido", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		svc := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+
+		_, err := svc.Search(ctx, userID, "  ", nil, 0)
+		assert.ErrorIs(t, err, service.ErrEmptySearch)
+
+		_, err = svc.Search(ctx, userID, "farmacia", nil, 201)
+		assert.ErrorIs(t, err, service.ErrInvalidLimit)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestExpenseService_GetByID(t *testing.T) {
 	mockRepo := new(MockExpenseRepository)
-	service := service.NewExpenseService(mockRepo)
+	service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
 	ctx := context.Background()
 	userID := "user123"
 	expenseID := "expense123"
@@ -103,7 +482,7 @@ func TestExpenseService_GetByID(t *testing.T) {
 		expected := &model.Expense{
 			ID:          expenseID,
 			UserID:      userID,
-			Amount:      100.50,
+			Amount:      model.MustParseMoney("100.50"),
 			Description: "Teste de despesa",
 			Category:    model.CategoryGroceries,
 			Date:        time.Now(),
@@ -131,7 +510,7 @@ func TestExpenseService_GetByID(t *testing.T) {
 
 func TestExpenseService_Update(t *testing.T) {
 	mockRepo := new(MockExpenseRepository)
-	service := service.NewExpenseService(mockRepo)
+	service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
 	ctx := context.Background()
 	userID := "user123"
 	expenseID := "expense123"
@@ -140,13 +519,13 @@ func TestExpenseService_Update(t *testing.T) {
 		existingExpense := &model.Expense{
 			ID:          expenseID,
 			UserID:      userID,
-			Amount:      100.50,
+			Amount:      model.MustParseMoney("100.50"),
 			Description: "Despesa original",
 			Category:    model.CategoryGroceries,
 			Date:        time.Now(),
 		}
 
-		newAmount := 150.75
+		newAmount := model.MustParseMoney("150.75")
 		newDescription := "Despesa atualizada"
 		input := &model.UpdateExpenseInput{
 			Amount:      &newAmount,
@@ -167,7 +546,7 @@ func TestExpenseService_Update(t *testing.T) {
 
 func TestExpenseService_Delete(t *testing.T) {
 	mockRepo := new(MockExpenseRepository)
-	service := service.NewExpenseService(mockRepo)
+	service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
 	ctx := context.Background()
 	userID := "user123"
 	expenseID := "expense123"
@@ -191,38 +570,189 @@ func TestExpenseService_Delete(t *testing.T) {
 	})
 }
 
-func TestExpenseService_GetExpensesByPeriod(t *testing.T) {
-	mockRepo := new(MockExpenseRepository)
-	service := service.NewExpenseService(mockRepo)
+func TestPeriodFilter(t *testing.T) {
+	tests := []struct {
+		period string
+		start  func(time.Time) time.Time
+	}{
+		{"week", func(now time.Time) time.Time { return now.AddDate(0, 0, -7) }},
+		{"month", func(now time.Time) time.Time { return now.AddDate(0, -1, 0) }},
+		{"quarter", func(now time.Time) time.Time { return now.AddDate(0, -3, 0) }},
+	}
+
+	for _, tt := range tests {
+		t.Run("deve filtrar o período "+tt.period+" até hoje", func(t *testing.T) {
+			before := time.Now()
+			filter := service.PeriodFilter(tt.period)
+			after := time.Now()
+
+			require.NotNil(t, filter)
+			require.NotNil(t, filter.StartDate)
+			require.NotNil(t, filter.EndDate)
+			assert.False(t, filter.StartDate.Before(tt.start(before)))
+			assert.False(t, filter.StartDate.After(tt.start(after)))
+			assert.False(t, filter.EndDate.Before(before))
+			assert.False(t, filter.EndDate.After(after))
+		})
+	}
+
+	t.Run("deve retornar nil para período desconhecido", func(t *testing.T) {
+		assert.Nil(t, service.PeriodFilter("year"))
+	})
+}
+
+func TestExpenseService_Summary(t *testing.T) {
 	ctx := context.Background()
 	userID := "user123"
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("deve somar os totais das categorias de forma exata", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+
+		groups := []model.ExpenseGroupTotal{
+			{CategoryID: "cat-lazer", Category: model.CategoryLeisure, Currency: "BRL", Date: date, Total: model.MustParseMoney("0.1"), Count: 1},
+			{CategoryID: "cat-outros", Category: model.CategoryOthers, Currency: "BRL", Date: date, Total: model.MustParseMoney("0.2"), Count: 2},
+		}
+		mockRepo.On("SummarizeGroups", ctx, userID, (*model.ExpenseFilter)(nil)).Return(groups, nil).Once()
+
+		summary, err := service.Summary(ctx, userID, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.MustParseMoney("0.3"), summary.Total)
+		assert.Equal(t, "0.30", summary.Total.String())
+		assert.Equal(t, 3, summary.Count)
+		assert.Len(t, summary.Categories, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve retornar listas vazias quando não há despesas", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL"))
+		mockRepo.On("SummarizeGroups", ctx, userID, (*model.ExpenseFilter)(nil)).Return(nil, nil).Once()
+
+		summary, err := service.Summary(ctx, userID, nil)
 
-	t.Run("deve retornar despesas do último mês", func(t *testing.T) {
-		expected := []*model.Expense{
-			{
-				ID:          "expense1",
-				UserID:      userID,
-				Amount:      100.50,
-				Description: "Despesa 1",
-				Category:    model.CategoryGroceries,
-				Date:        time.Now().AddDate(0, 0, -15),
-			},
-			{
-				ID:          "expense2",
-				UserID:      userID,
-				Amount:      200.75,
-				Description: "Despesa 2",
-				Category:    model.CategoryGroceries,
-				Date:        time.Now().AddDate(0, 0, -5),
-			},
+		assert.NoError(t, err)
+		assert.Equal(t, model.Money(0), summary.Total)
+		assert.NotNil(t, summary.Categories)
+		assert.NotNil(t, summary.Currencies)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve converter cada moeda com a cotação da data", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		service := service.NewExpenseService(mockRepo, newFixedCategories(), newFixedCurrency("BRL",
+			model.ExchangeRate{Date: date, Currency: "USD", Rate: "1.08"},
+			model.ExchangeRate{Date: date, Currency: "BRL", Rate: "5.40"},
+		))
+
+		groups := []model.ExpenseGroupTotal{
+			{CategoryID: "cat-lazer", Category: model.CategoryLeisure, Currency: "BRL", Date: date, Total: model.MustParseMoney("5.00"), Count: 1},
+			{CategoryID: "cat-lazer", Category: model.CategoryLeisure, Currency: "USD", Date: date.AddDate(0, 0, 3), Total: model.MustParseMoney("10.00"), Count: 1},
+			{CategoryID: "cat-outros", Category: model.CategoryOthers, Currency: "USD", Date: date.AddDate(-1, 0, 0), Total: model.MustParseMoney("7.00"), Count: 1},
+		}
+		mockRepo.On("SummarizeGroups", ctx, userID, (*model.ExpenseFilter)(nil)).Return(groups, nil).Once()
+
+		summary, err := service.Summary(ctx, userID, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "BRL", summary.BaseCurrency)
+		assert.Equal(t, model.MustParseMoney("55.00"), summary.Total)
+		assert.Equal(t, 3, summary.Count)
+		assert.True(t, summary.Incomplete)
+
+		assert.Equal(t, []model.CategoryTotal{
+			{CategoryID: "cat-lazer", Category: model.CategoryLeisure, Total: model.MustParseMoney("55.00"), Count: 2},
+			{CategoryID: "cat-outros", Category: model.CategoryOthers, Total: 0, Count: 1},
+		}, summary.Categories)
+
+		brl := model.MustParseMoney("5.00")
+		assert.Equal(t, []model.CurrencyTotal{
+			{Currency: "BRL", Amount: brl, BaseAmount: &brl, Count: 1},
+			{Currency: "USD", Amount: model.MustParseMoney("17.00"), BaseAmount: nil, Count: 2},
+		}, summary.Currencies)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("deve somar as subcategorias nas categorias acima", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		categories := newFixedCategories().
+			withChild(model.CategoryGroceries, "Restaurante").
+			withChild("Restaurante", "Delivery").
+			withChild(model.CategoryGroceries, "Feira")
+		service := service.NewExpenseService(mockRepo, categories, newFixedCurrency("BRL"))
+
+		groups := []model.ExpenseGroupTotal{
+			{CategoryID: "cat-delivery", Category: "Delivery", Currency: "BRL", Date: date, Total: model.MustParseMoney("30.00"), Count: 1},
+			{CategoryID: "cat-feira", Category: "Feira", Currency: "BRL", Date: date, Total: model.MustParseMoney("12.50"), Count: 2},
+			{CategoryID: "cat-mantimentos", Category: model.CategoryGroceries, Currency: "BRL", Date: date, Total: model.MustParseMoney("100.00"), Count: 1},
+			{CategoryID: "cat-restaurante", Category: "Restaurante", Currency: "BRL", Date: date, Total: model.MustParseMoney("45.00"), Count: 1},
 		}
+		mockRepo.On("SummarizeGroups", ctx, userID, (*model.ExpenseFilter)(nil)).Return(groups, nil).Once()
+
+		summary, err := service.Summary(ctx, userID, nil)
 
-		mockRepo.On("List", ctx, userID, mock.AnythingOfType("*model.ExpenseFilter")).Return(expected, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, model.MustParseMoney("187.50"), summary.Total)
+		assert.Equal(t, 5, summary.Count)
+
+		groceries, restaurant := "cat-mantimentos", "cat-restaurante"
+		assert.Equal(t, []model.CategoryTotal{
+			{CategoryID: "cat-mantimentos", Category: model.CategoryGroceries, Total: model.MustParseMoney("187.50"), Count: 5},
+			{CategoryID: "cat-feira", ParentID: &groceries, Category: "Feira", Total: model.MustParseMoney("12.50"), Count: 2},
+			{CategoryID: "cat-restaurante", ParentID: &groceries, Category: "Restaurante", Total: model.MustParseMoney("75.00"), Count: 2},
+			{CategoryID: "cat-delivery", ParentID: &restaurant, Category: "Delivery", Total: model.MustParseMoney("30.00"), Count: 1},
+		}, summary.Categories)
+		mockRepo.AssertExpectations(t)
+	})
+}
 
-		expenses, err := service.GetExpensesByPeriod(ctx, userID, "month")
+func TestExpenseService_Currency(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	converter := newFixedCurrency("BRL",
+		model.ExchangeRate{Date: date, Currency: "USD", Rate: "1.08"},
+		model.ExchangeRate{Date: date, Currency: "BRL", Rate: "5.40"},
+	)
+
+	t.Run("deve converter a despesa para a moeda base", func(t *testing.T) {
+		mockRepo := new(MockExpenseRepository)
+		service := service.NewExpenseService(mockRepo, newFixedCategories(), converter)
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*model.Expense")).Return(nil).Once()
+
+		expense, err := service.Create(ctx, userID, &model.CreateExpenseInput{
+			Amount:      model.MustParseMoney("10.00"),
+			Currency:    "usd",
+			Description: "Jantar",
+			Category:    model.CategoryLeisure,
+			Date:        "2024-03-04",
+		})
 
 		assert.NoError(t, err)
-		assert.Equal(t, expected, expenses)
+		assert.Equal(t, "USD", expense.Currency)
+		assert.Equal(t, "BRL", expense.BaseCurrency)
+		if assert.NotNil(t, expense.BaseAmount) {
+			assert.Equal(t, model.MustParseMoney("50.00"), *expense.BaseAmount)
+		}
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("deve recusar moedas inválidas ou sem cotação", func(t *testing.T) {
+		service := service.NewExpenseService(new(MockExpenseRepository), newFixedCategories(), converter)
+
+		for _, code := range []string{"JPY", "dolar"} {
+			expense, err := service.Create(ctx, userID, &model.CreateExpenseInput{
+				Amount:      model.MustParseMoney("10.00"),
+				Currency:    code,
+				Description: "Jantar",
+				Category:    model.CategoryLeisure,
+				Date:        "2024-03-04",
+			})
+
+			assert.Error(t, err, code)
+			assert.Nil(t, expense)
+		}
+	})
 }
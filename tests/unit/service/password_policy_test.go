@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"expenseapi/internal/password"
+	"expenseapi/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// violatedRules retorna as regras não atendidas em err
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+	var policyErr *service.PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr), "esperado *PasswordPolicyError, obtido %v", err)
+
+	rules := make([]string, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	// Lista com o SHA-1 de "password123", no formato do Have I Been Pwned
+	dir := t.TempDir()
+	listPath := filepath.Join(dir, "breached.txt")
+	require.NoError(t, os.WriteFile(listPath, []byte("# senhas vazadas\nCBFDAC6008F9CAB4083784CBD1874F76618D2A97:2470634\n"), 0o600))
+	breached, err := password.LoadBreachedList(listPath)
+	require.NoError(t, err)
+
+	policy, err := service.NewPasswordPolicy(10, []string{service.PasswordClassUpper, service.PasswordClassDigit}, breached)
+	require.NoError(t, err)
+
+	t.Run("senha_valida", func(t *testing.T) {
+		assert.NoError(t, policy.Validate("Cavalo-Correto-42", "user@example.com"))
+	})
+
+	t.Run("lista_todas_as_violacoes", func(t *testing.T) {
+		err := policy.Validate("abc", "user@example.com")
+		assert.ErrorIs(t, err, service.ErrWeakPassword)
+		assert.Equal(t, []string{service.PasswordRuleMinLength, service.PasswordClassUpper, service.PasswordClassDigit}, violatedRules(t, err))
+		assert.Equal(t, "senha deve ter no mínimo 10 caracteres", err.Error())
+	})
+
+	t.Run("limite_de_72_bytes", func(t *testing.T) {
+		// 36 caracteres de 2 bytes cada: dentro do mínimo, acima do limite em bytes
+		err := policy.Validate("A1"+strings.Repeat("é", 36), "user@example.com")
+		assert.Equal(t, []string{service.PasswordRuleMaxLength}, violatedRules(t, err))
+
+		assert.NoError(t, policy.Validate("A1"+strings.Repeat("a", 70), "user@example.com"))
+	})
+
+	t.Run("senha_igual_ao_email", func(t *testing.T) {
+		err := policy.Validate("User1@Example.com", "user1@example.com")
+		assert.Equal(t, []string{service.PasswordRuleEmail}, violatedRules(t, err))
+	})
+
+	t.Run("senha_vazada", func(t *testing.T) {
+		lenient, err := service.NewPasswordPolicy(6, nil, breached)
+		require.NoError(t, err)
+
+		err = lenient.Validate("password123", "user@example.com")
+		assert.Equal(t, []string{service.PasswordRuleBreached}, violatedRules(t, err))
+		assert.NoError(t, lenient.Validate("password124", "user@example.com"))
+	})
+
+	t.Run("classe_desconhecida", func(t *testing.T) {
+		_, err := service.NewPasswordPolicy(8, []string{"emoji"}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("lista_com_hash_invalido", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.txt")
+		require.NoError(t, os.WriteFile(invalid, []byte("nao-e-um-hash:1\n"), 0o600))
+		_, err := password.LoadBreachedList(invalid)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"expenseapi/internal/auth"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// Segredo ASCII "12345678901234567890" dos vetores de teste da RFC 6238
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	t.Run("vetores_rfc_6238", func(t *testing.T) {
+		tests := []struct {
+			unix     int64
+			expected string
+		}{
+			{unix: 59, expected: "287082"},
+			{unix: 1111111109, expected: "081804"},
+			{unix: 1111111111, expected: "050471"},
+			{unix: 1234567890, expected: "005924"},
+			{unix: 2000000000, expected: "279037"},
+		}
+
+		for _, tt := range tests {
+			code, err := auth.TOTPCode(secret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("erro ao gerar código: %v", err)
+			}
+			if code != tt.expected {
+				t.Errorf("código esperado %q para t=%d, obtido %q", tt.expected, tt.unix, code)
+			}
+		}
+	})
+
+	t.Run("validacao_com_tolerancia", func(t *testing.T) {
+		now := time.Unix(1234567890, 0)
+		code, _ := auth.TOTPCode(secret, now.Add(-30*time.Second))
+
+		step, ok := auth.ValidateTOTP(secret, code, now)
+		if !ok {
+			t.Fatal("esperado código do intervalo anterior aceito")
+		}
+		if step != now.Unix()/30-1 {
+			t.Errorf("intervalo esperado %d, obtido %d", now.Unix()/30-1, step)
+		}
+
+		if _, ok := auth.ValidateTOTP(secret, code, now.Add(2*time.Minute)); ok {
+			t.Error("esperado código antigo rejeitado")
+		}
+		if _, ok := auth.ValidateTOTP(secret, "12345", now); ok {
+			t.Error("esperado código com tamanho inválido rejeitado")
+		}
+	})
+
+	t.Run("segredo_e_uri", func(t *testing.T) {
+		generated, err := auth.GenerateTOTPSecret()
+		if err != nil {
+			t.Fatalf("erro ao gerar segredo: %v", err)
+		}
+		if len(generated) != 32 {
+			t.Errorf("tamanho esperado 32, obtido %d", len(generated))
+		}
+
+		uri := auth.TOTPURI("Expense API", "user@example.com", generated)
+		if !strings.HasPrefix(uri, "otpauth://totp/Expense%20API:user@example.com?") || !strings.Contains(uri, "secret="+generated) {
+			t.Errorf("URI inesperada: %s", uri)
+		}
+	})
+}